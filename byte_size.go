@@ -0,0 +1,125 @@
+package confuse
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that can be configured with a unit, such as 512MB or 1.5GiB.
+// Units are case-insensitive: KB, MB, GB, TB and PB are powers of 1000, and KiB, MiB, GiB, TiB and PiB are powers of 1024.
+// The B can be left out, e.g. 10k, and a number without a unit is a number of bytes.
+type ByteSize uint64
+
+// The sizes of the units ByteSize supports.
+const (
+	Byte ByteSize = 1
+
+	Kilobyte = 1000 * Byte
+	Megabyte = 1000 * Kilobyte
+	Gigabyte = 1000 * Megabyte
+	Terabyte = 1000 * Gigabyte
+	Petabyte = 1000 * Terabyte
+
+	Kibibyte = 1024 * Byte
+	Mebibyte = 1024 * Kibibyte
+	Gibibyte = 1024 * Mebibyte
+	Tebibyte = 1024 * Gibibyte
+	Pebibyte = 1024 * Tebibyte
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   Kilobyte,
+	"kb":  Kilobyte,
+	"m":   Megabyte,
+	"mb":  Megabyte,
+	"g":   Gigabyte,
+	"gb":  Gigabyte,
+	"t":   Terabyte,
+	"tb":  Terabyte,
+	"p":   Petabyte,
+	"pb":  Petabyte,
+	"ki":  Kibibyte,
+	"kib": Kibibyte,
+	"mi":  Mebibyte,
+	"mib": Mebibyte,
+	"gi":  Gibibyte,
+	"gib": Gibibyte,
+	"ti":  Tebibyte,
+	"tib": Tebibyte,
+	"pi":  Pebibyte,
+	"pib": Pebibyte,
+}
+
+// ByteSizePattern is the regular expression the strings ByteSize parses match, used in the JSON schema.
+const ByteSizePattern = `^\s*[0-9]+(\.[0-9]+)?\s*([kKmMgGtTpP][iI]?)?[bB]?\s*$`
+
+// ParseByteSize parses a size with an optional unit, such as 512MB or 1.5GiB.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.TrimSpace(s)
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end == -1 {
+		end = len(value)
+	}
+
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil || number < 0 {
+		return 0, errors.New("invalid byte size " + strconv.Quote(s))
+	}
+
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(value[end:]))]
+	if !ok {
+		return 0, errors.New("unknown unit in byte size " + strconv.Quote(s))
+	}
+
+	size := number * float64(unit)
+	if size >= math.MaxUint64 {
+		return 0, errors.New("byte size " + strconv.Quote(s) + " is too large")
+	}
+
+	return ByteSize(size), nil
+}
+
+// String returns the size with the largest unit that represents it exactly, preferring the powers of 1024, e.g. 512MiB.
+func (b ByteSize) String() string {
+	units := []struct {
+		name string
+		size ByteSize
+	}{
+		{"PiB", Pebibyte}, {"PB", Petabyte},
+		{"TiB", Tebibyte}, {"TB", Terabyte},
+		{"GiB", Gibibyte}, {"GB", Gigabyte},
+		{"MiB", Mebibyte}, {"MB", Megabyte},
+		{"KiB", Kibibyte}, {"KB", Kilobyte},
+	}
+
+	for _, unit := range units {
+		if b != 0 && b%unit.size == 0 {
+			return strconv.FormatUint(uint64(b/unit.size), 10) + unit.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package confuse
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"dario.cat/mergo"
+)
+
+// canonicalKeys renames the keys of the nested map that match a field of the type t to the name the decoder matches first, recursing into nested structs, slices and maps.
+// Sources spell the same key in different ways, e.g. DatabaseConfig and database_config, which would otherwise be merged as separate keys.
+// Keys that do not match a field are left as they are.
+func (s *Service) canonicalKeys(value any, t reflect.Type) (any, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil {
+		return value, nil
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			if isValueType(t) {
+				return value, nil
+			}
+
+			return s.canonicalStructKeys(typed, t)
+		case reflect.Map, reflect.Slice, reflect.Array:
+			// Slices can be set by maps of indexes, like the ones built from APP__SERVERS__0__HOST
+			result := make(map[string]any, len(typed))
+			for key, nested := range typed {
+				canonical, err := s.canonicalKeys(nested, t.Elem())
+				if err != nil {
+					return nil, err
+				}
+
+				result[key] = canonical
+			}
+
+			return result, nil
+		}
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return value, nil
+		}
+
+		result := make([]any, len(typed))
+		for i, item := range typed {
+			canonical, err := s.canonicalKeys(item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = canonical
+		}
+
+		return result, nil
+	}
+
+	return value, nil
+}
+
+// canonicalStructKeys renames the keys of a map that is decoded into the struct type t.
+// Where the map spells a key in several ways, the values are merged in a fixed order, with the exact name taking precedence.
+func (s *Service) canonicalStructKeys(m map[string]any, t reflect.Type) (map[string]any, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		iExact, jExact := s.isDecoderName(t, keys[i]), s.isDecoderName(t, keys[j])
+		if iExact != jExact {
+			return jExact
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	result := make(map[string]any, len(m))
+	for _, key := range keys {
+		name, value := key, m[key]
+		if field, ok := s.lookupField(t, key); ok {
+			name = decoderName(field)
+
+			var err error
+			value, err = s.canonicalKeys(value, field.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		existing, existingOk := result[name].(map[string]any)
+		nested, nestedOk := value.(map[string]any)
+		if existingOk && nestedOk {
+			merged := deepCopy(existing).(map[string]any)
+			err := mergo.Merge(&merged, nested, s.MergoConfig...)
+			if err != nil {
+				return nil, err
+			}
+
+			value = merged
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// isDecoderName reports whether the key is already named the way canonicalKeys names it.
+func (s *Service) isDecoderName(t reflect.Type, key string) bool {
+	field, ok := s.lookupField(t, key)
+
+	return !ok || decoderName(field) == key
+}
+
+// canonicalSource renames the keys of a source before it is merged, see canonicalKeys.
+func (s *Service) canonicalSource(source map[string]any, t reflect.Type) (map[string]any, error) {
+	result, err := s.canonicalKeys(source, t)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, ok := result.(map[string]any)
+	if !ok {
+		return source, nil
+	}
+
+	return canonical, nil
+}
+
+// canonicalNames re-keys the names of the environment variables or flags that set each value, by the dot separated path canonicalKeys gives the value.
+func (s *Service) canonicalNames(t reflect.Type, names map[string]string) map[string]string {
+	result := make(map[string]string, len(names))
+	for key, name := range names {
+		result[strings.Join(s.decoderPath(t, strings.Split(key, ".")), ".")] = name
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ls6-events/confuse"
+)
+
+func convertCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, "Usage: confuse convert [flags] <file>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	to := flags.String("to", "", "The format to convert to, such as yaml, json, toml or env, which defaults to the extension of --output")
+	output := flags.String("output", "", "The file to write, instead of printing the result")
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "confuse: convert requires a single file")
+		flags.Usage()
+		return errUsage
+	}
+
+	format := *to
+	if format == "" {
+		format = filepath.Ext(*output)
+	}
+	if format == "" {
+		fmt.Fprintln(stderr, "confuse: convert requires --to or --output")
+		flags.Usage()
+		return errUsage
+	}
+
+	if *output != "" && sameFile(*output, flags.Arg(0)) {
+		fmt.Fprintln(stderr, "confuse: convert cannot write to the file it reads")
+		flags.Usage()
+		return errUsage
+	}
+
+	// The result is rendered before the output file is written, so it is left as it is when the conversion fails
+	var rendered bytes.Buffer
+	values := map[string]any{}
+	err = confuse.New(confuse.WithSourceFiles(flags.Arg(0))).Render(&values, format, &rendered)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(rendered.Bytes())
+		return err
+	}
+
+	return os.WriteFile(*output, rendered.Bytes(), 0644)
+}
+
+// sameFile reports whether the two paths point to the same file, or would if the first one was created.
+func sameFile(a string, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	if aErr == nil && bErr == nil {
+		return os.SameFile(aInfo, bInfo)
+	}
+
+	aPath, aErr := filepath.Abs(a)
+	bPath, bErr := filepath.Abs(b)
+
+	return aErr == nil && bErr == nil && aPath == bPath
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func explainCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags, sources := newFlagSet("explain", "explain [flags] <key.path> <files...>", stderr)
+	err := parse(flags, sources, args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		fmt.Fprintln(stderr, "confuse: explain requires a key and at least one file")
+		flags.Usage()
+		return errUsage
+	}
+
+	key := flags.Arg(0)
+	values := map[string]any{}
+	provenance, err := sources.service(flags.Args()[1:]).Explain(&values)
+	if err != nil {
+		return err
+	}
+
+	// The key can also be the parent of the keys to explain, such as database for database.host and database.port
+	found := false
+	for _, valueKey := range provenance.Keys() {
+		if valueKey != key && !strings.HasPrefix(valueKey, key+".") {
+			continue
+		}
+
+		found = true
+		value := provenance[valueKey]
+		fmt.Fprintf(stdout, "%s = %v\n", valueKey, value.Value)
+		fmt.Fprintf(stdout, "  set by %s\n", value.Origin)
+		for i := len(value.Overridden) - 1; i >= 0; i-- {
+			fmt.Fprintf(stdout, "  overrides %v from %s\n", value.Overridden[i].Value, value.Overridden[i].Origin)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("key %s is not set", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// Command confuse works with configuration files without writing Go.
+// It merges the files the same way as confuse.Service, in the order they are given, and can validate the result against a JSON schema,
+// render it in another format, convert single files between formats, and explain which file set each key.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ls6-events/confuse"
+)
+
+const usage = `Usage: confuse <command> [flags] <files...>
+
+The files are merged in order, so the keys in later files override the ones in earlier files.
+
+Commands:
+  validate  Merge the files and validate the result against a JSON schema
+  render    Merge the files and print the result
+  convert   Convert a file to another format
+  explain   Show which file set each key
+
+Run "confuse <command> -h" for the flags of a command.
+`
+
+// errUsage is returned for invalid arguments, after the usage has been printed.
+var errUsage = errors.New("invalid arguments")
+
+// command is a subcommand, which is run with the arguments after its name.
+type command func(args []string, stdout io.Writer, stderr io.Writer) error
+
+var commands = map[string]command{
+	"validate": validateCommand,
+	"render":   renderCommand,
+	"convert":  convertCommand,
+	"explain":  explainCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command in args, and returns the exit code: 0 on success, 1 on failure and 2 for invalid arguments.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "confuse: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	err := cmd(args[1:], stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "confuse: %v\n", err)
+		return 1
+	}
+}
+
+// sourceFlags are the flags shared by the commands that merge files.
+type sourceFlags struct {
+	env         bool
+	envPrefix   string
+	interpolate bool
+}
+
+func newFlagSet(name string, description string, stderr io.Writer) (*flag.FlagSet, *sourceFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: confuse %s\n\nFlags:\n", description)
+		flags.PrintDefaults()
+	}
+
+	sources := &sourceFlags{}
+	flags.BoolVar(&sources.env, "env", false, "Override the files with environment variables, which requires --env-prefix")
+	flags.StringVar(&sources.envPrefix, "env-prefix", "", "Only use the environment variables with this prefix, implies --env")
+	flags.BoolVar(&sources.interpolate, "interpolate", false, "Resolve ${path.to.key} and ${env:NAME} references")
+
+	return flags, sources
+}
+
+// parse parses the flags, and checks that at least one file is given.
+// --env is rejected without --env-prefix, as it would merge every variable of the environment.
+func parse(flags *flag.FlagSet, sources *sourceFlags, args []string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(flags.Output(), "confuse: no files given")
+		flags.Usage()
+		return errUsage
+	}
+
+	if sources.env && sources.envPrefix == "" {
+		fmt.Fprintln(flags.Output(), "confuse: --env requires --env-prefix")
+		flags.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// service returns the service that merges the files with the flags.
+func (f *sourceFlags) service(files []string) *confuse.Service {
+	opts := []confuse.Option{confuse.WithSourceFiles(files...)}
+	if f.envPrefix != "" {
+		opts = append(opts, confuse.WithEnvironmentVariables(true), confuse.WithEnvironmentVariablesPrefix(f.envPrefix))
+	}
+	if f.interpolate {
+		opts = append(opts, confuse.WithInterpolation())
+	}
+
+	return confuse.New(opts...)
+}
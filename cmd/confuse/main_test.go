@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	override := filepath.Join(dir, "override.json")
+	schema := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(base, []byte("database:\n  host: localhost\n  port: 5432\nname: app\n"), 0644))
+	require.NoError(t, os.WriteFile(override, []byte(`{"database": {"port": 5433}}`), 0644))
+	require.NoError(t, os.WriteFile(schema, []byte(`{
+		"type": "object",
+		"required": ["database"],
+		"properties": {
+			"database": {
+				"type": "object",
+				"properties": {"port": {"type": "integer", "maximum": 6000}}
+			}
+		}
+	}`), 0644))
+
+	runCommand := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("should validate the merged files against the schema", func(t *testing.T) {
+		code, stdout, _ := runCommand("validate", "--schema", schema, base, override)
+		require.Equal(t, 0, code)
+		require.Equal(t, "ok\n", stdout)
+
+		invalid := filepath.Join(dir, "invalid.yaml")
+		require.NoError(t, os.WriteFile(invalid, []byte("database:\n  port: 7000\n"), 0644))
+
+		code, _, stderr := runCommand("validate", "--schema", schema, base, invalid)
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "/database/port")
+		require.Contains(t, stderr, "must be <= 6000")
+	})
+
+	t.Run("should render the merged files", func(t *testing.T) {
+		code, stdout, _ := runCommand("render", "--format", "toml", base, override)
+		require.Equal(t, 0, code)
+		require.Equal(t, "name = 'app'\n\n[database]\nhost = 'localhost'\nport = 5433\n", stdout)
+	})
+
+	t.Run("should override the files with environment variables", func(t *testing.T) {
+		t.Setenv("CLI_TEST_DATABASE__HOST", "db.internal")
+
+		code, stdout, _ := runCommand("render", "--format", "env", "--env-prefix", "CLI_TEST_", base)
+		require.Equal(t, 0, code)
+		require.Contains(t, stdout, "CLI_TEST_DATABASE__HOST=db.internal\n")
+	})
+
+	t.Run("should not merge the whole environment", func(t *testing.T) {
+		code, _, stderr := runCommand("render", "--env", base)
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "--env requires --env-prefix")
+
+		code, _, _ = runCommand("render", "--env", "--env-prefix", "CLI_TEST_", base)
+		require.Equal(t, 0, code)
+	})
+
+	t.Run("should convert a file", func(t *testing.T) {
+		output := filepath.Join(dir, "converted.json")
+		code, _, _ := runCommand("convert", "--output", output, base)
+		require.Equal(t, 0, code)
+
+		converted, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"database": {"host": "localhost", "port": 5432}, "name": "app"}`, string(converted))
+	})
+
+	t.Run("should leave the files as they are when the conversion fails", func(t *testing.T) {
+		code, _, stderr := runCommand("convert", "--output", base, base)
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, "cannot write to the file it reads")
+
+		contents, err := os.ReadFile(base)
+		require.NoError(t, err)
+		require.Equal(t, "database:\n  host: localhost\n  port: 5432\nname: app\n", string(contents))
+
+		unsupported := filepath.Join(dir, "converted.xyz")
+		code, _, _ = runCommand("convert", "--output", unsupported, base)
+		require.Equal(t, 1, code)
+		require.NoFileExists(t, unsupported)
+	})
+
+	t.Run("should explain which file set a key", func(t *testing.T) {
+		code, stdout, _ := runCommand("explain", "database", base, override)
+		require.Equal(t, 0, code)
+		require.Equal(t, "database.host = localhost\n"+
+			"  set by file "+base+"\n"+
+			"database.port = 5433\n"+
+			"  set by file "+override+"\n"+
+			"  overrides 5432 from file "+base+"\n", stdout)
+
+		code, _, stderr := runCommand("explain", "missing", base)
+		require.Equal(t, 1, code)
+		require.Contains(t, stderr, "key missing is not set")
+	})
+
+	t.Run("should fail on invalid arguments", func(t *testing.T) {
+		code, _, stderr := runCommand("unknown")
+		require.Equal(t, 2, code)
+		require.Contains(t, stderr, `unknown command "unknown"`)
+
+		code, _, _ = runCommand("render")
+		require.Equal(t, 2, code)
+
+		code, _, _ = runCommand("convert", base)
+		require.Equal(t, 2, code)
+	})
+}
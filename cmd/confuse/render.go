@@ -0,0 +1,18 @@
+package main
+
+import (
+	"io"
+)
+
+func renderCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags, sources := newFlagSet("render", "render [flags] <files...>", stderr)
+	format := flags.String("format", "yaml", "The format to print the merged files in, such as yaml, json, toml or env")
+	err := parse(flags, sources, args)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+
+	return sources.service(flags.Args()).Render(&values, *format, stdout)
+}
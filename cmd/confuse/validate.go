@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+func validateCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags, sources := newFlagSet("validate", "validate [flags] <files...>", stderr)
+	schemaPath := flags.String("schema", "", "The JSON schema to validate the merged files against")
+	err := parse(flags, sources, args)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	err = sources.service(flags.Args()).Unmarshal(&values)
+	if err != nil {
+		return err
+	}
+
+	if *schemaPath != "" {
+		schema, err := jsonschema.Compile(*schemaPath)
+		if err != nil {
+			return err
+		}
+
+		// The validator only supports the types encoding/json decodes into, so the values are converted to them
+		document, err := toJSONValue(values)
+		if err != nil {
+			return err
+		}
+
+		err = schema.Validate(document)
+		var validationError *jsonschema.ValidationError
+		if errors.As(err, &validationError) {
+			return fmt.Errorf("%#v", validationError)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(stdout, "ok")
+
+	return nil
+}
+
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result any
+	err = decoder.Decode(&result)
+
+	return result, err
+}
@@ -0,0 +1,61 @@
+package confuse
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// decodeHook returns the hook that converts the merged values into the types of the fields when decoding.
+// The hooks set with WithDecodeHook run first, followed by the built-in ones.
+func (s *Service) decodeHook() mapstructure.DecodeHookFunc {
+	hooks := append([]mapstructure.DecodeHookFunc{}, s.DecodeHooks...)
+	hooks = append(hooks,
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToURLHook(),
+		textUnmarshalerHook(),
+		s.secretHook(),
+	)
+
+	return mapstructure.ComposeDecodeHookFunc(hooks...)
+}
+
+var (
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// stringToURLHook parses strings into url.URL fields, and the url.URL values pointer fields point to.
+func stringToURLHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || to != urlType {
+			return data, nil
+		}
+
+		result, err := url.Parse(reflect.ValueOf(data).String())
+		if err != nil {
+			return nil, err
+		}
+
+		return *result, nil
+	}
+}
+
+// textUnmarshalerHook converts strings into the types that implement encoding.TextUnmarshaler, such as time.Time, net.IP, regexp.Regexp and ByteSize.
+func textUnmarshalerHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || !reflect.PointerTo(to).Implements(textUnmarshalerType) {
+			return data, nil
+		}
+
+		result := reflect.New(to)
+		err := result.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(reflect.ValueOf(data).String()))
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Elem().Interface(), nil
+	}
+}
@@ -0,0 +1,171 @@
+package confuse
+
+import (
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ls6-events/validjsonator"
+	"github.com/stretchr/testify/require"
+)
+
+type upperString string
+
+func TestService_DecodeHooks(t *testing.T) {
+	type testStruct struct {
+		Timeout   time.Duration
+		StartedAt time.Time
+		Address   net.IP
+		Endpoint  *url.URL
+		Homepage  url.URL
+		Pattern   *regexp.Regexp
+		MaxUpload ByteSize
+		Cache     ByteSize
+		Name      upperString
+		Level     slog.Level
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`timeout: 1m30s
+started_at: 2024-01-02T03:04:05Z
+address: 10.0.0.1
+endpoint: https://example.com/api?debug=true
+homepage: https://example.com
+pattern: "^a+b$"
+max_upload: 1.5GiB
+cache: 1024
+name: confuse
+level: warn
+`), 0644))
+
+	upperHook := func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if to != reflect.TypeOf(upperString("")) {
+			return data, nil
+		}
+
+		return strings.ToUpper(data.(string)), nil
+	}
+
+	t.Run("should decode the built-in types and use the custom hooks", func(t *testing.T) {
+		var result testStruct
+		err := New(WithSourceFiles(config), WithDecodeHook(upperHook)).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, 90*time.Second, result.Timeout)
+		require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), result.StartedAt)
+		require.Equal(t, net.ParseIP("10.0.0.1"), result.Address)
+		require.Equal(t, "https://example.com/api?debug=true", result.Endpoint.String())
+		require.Equal(t, "example.com", result.Homepage.Host)
+		require.True(t, result.Pattern.MatchString("aab"))
+		require.Equal(t, ByteSize(1.5*float64(Gibibyte)), result.MaxUpload)
+		require.Equal(t, Kibibyte, result.Cache)
+		require.Equal(t, upperString("CONFUSE"), result.Name)
+		require.Equal(t, slog.LevelWarn, result.Level)
+	})
+
+	t.Run("should decode the built-in types from the environment", func(t *testing.T) {
+		t.Setenv("DECODE_HOOKS_TEST_TIMEOUT", "250ms")
+		t.Setenv("DECODE_HOOKS_TEST_MAX_UPLOAD", "10MB")
+
+		var result testStruct
+		err := New(WithSourceFiles(config), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("DECODE_HOOKS_TEST_")).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, 250*time.Millisecond, result.Timeout)
+		require.Equal(t, 10*Megabyte, result.MaxUpload)
+	})
+
+	t.Run("should fail on invalid values", func(t *testing.T) {
+		t.Setenv("DECODE_HOOKS_TEST_MAX_UPLOAD", "10XB")
+
+		var result testStruct
+		err := New(WithSourceFiles(config), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("DECODE_HOOKS_TEST_")).Unmarshal(&result)
+		require.ErrorContains(t, err, `unknown unit in byte size "10XB"`)
+	})
+
+	t.Run("should generate string schemas", func(t *testing.T) {
+		schema, err := New().schemaFromType(reflect.TypeOf(testStruct{}), false)
+		require.NoError(t, err)
+
+		for key, format := range map[string]string{"started_at": "date-time", "endpoint": "uri", "homepage": "uri", "pattern": "regex"} {
+			require.Equal(t, "string", schema.Properties[key].Type, key)
+			require.Equal(t, format, schema.Properties[key].Format, key)
+		}
+
+		require.Equal(t, "string", schema.Properties["timeout"].Type)
+		require.Regexp(t, schema.Properties["timeout"].Pattern, "1h2m3.5s")
+		require.Equal(t, "string", schema.Properties["max_upload"].Type)
+		require.Regexp(t, schema.Properties["max_upload"].Pattern, "1.5GiB")
+		require.Equal(t, "string", schema.Properties["address"].Type)
+		require.Len(t, schema.Properties["address"].AnyOf, 2)
+
+		// Text types with a scalar kind can be set with either
+		require.Empty(t, schema.Properties["level"].Type)
+		require.Equal(t, []validjsonator.Schema{{Type: "string"}, {Type: "integer"}}, schema.Properties["level"].AnyOf)
+	})
+
+	t.Run("should decode text types with a scalar kind from either", func(t *testing.T) {
+		for _, level := range []string{"warn", "4"} {
+			path := filepath.Join(dir, "level.yaml")
+			require.NoError(t, os.WriteFile(path, []byte("level: "+level+"\n"), 0644))
+
+			var result testStruct
+			err := New(WithSourceFiles(path)).Unmarshal(&result)
+			require.NoError(t, err, level)
+			require.Equal(t, slog.LevelWarn, result.Level, level)
+		}
+	})
+
+	t.Run("should write defaults in the schema the way they are read", func(t *testing.T) {
+		type defaultsStruct struct {
+			Timeout  time.Duration `config:"timeout,default=30s"`
+			Endpoint url.URL       `config:"endpoint,default=https://example.com"`
+		}
+
+		schema, err := New().schemaFromType(reflect.TypeOf(defaultsStruct{}), false)
+		require.NoError(t, err)
+		require.Equal(t, "30s", schema.Properties["timeout"].Default)
+		require.Equal(t, "https://example.com", schema.Properties["endpoint"].Default)
+	})
+
+	t.Run("should not recurse into value types", func(t *testing.T) {
+		fields := New().leafFields(reflect.TypeOf(testStruct{}), nil)
+		require.Len(t, fields, reflect.TypeOf(testStruct{}).NumField())
+	})
+}
+
+func TestParseByteSize(t *testing.T) {
+	for input, expected := range map[string]ByteSize{
+		"0":       0,
+		"512":     512,
+		"10k":     10 * Kilobyte,
+		"1 MB":    Megabyte,
+		"2gib":    2 * Gibibyte,
+		"1.5KiB":  1536,
+		" 3TB ":   3 * Terabyte,
+		"1Pi":     Pebibyte,
+		"100b":    100,
+		"0.5MiB":  512 * Kibibyte,
+		"7.25 kb": 7250,
+	} {
+		size, err := ParseByteSize(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, size, input)
+	}
+
+	for _, input := range []string{"", "MB", "-1MB", "1.2.3MB", "1XB"} {
+		_, err := ParseByteSize(input)
+		require.Error(t, err, input)
+	}
+
+	require.Equal(t, "512MiB", (512 * Mebibyte).String())
+	require.Equal(t, "3MB", (3 * Megabyte).String())
+	require.Equal(t, "1023B", ByteSize(1023).String())
+	require.Equal(t, "0B", ByteSize(0).String())
+}
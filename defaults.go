@@ -0,0 +1,129 @@
+package confuse
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const defaultOption = "default"
+
+// defaultsFromType builds the lowest precedence configuration layer from the `default` option of the config tag of every field.
+// The values are left as strings, and are converted to the type of the field when they are decoded.
+func (s *Service) defaultsFromType(t reflect.Type) map[string]any {
+	result := make(map[string]any)
+	for _, field := range s.leafFields(t, nil) {
+		if value, ok := field.Options[defaultOption]; ok {
+			setPath(result, field.Path, value)
+		}
+	}
+
+	return result
+}
+
+// defaultValue converts the `default` option of the field's config tag to the field's type, for use in the JSON schema.
+func (s *Service) defaultValue(field reflect.StructField) (any, bool, error) {
+	_, options := extractValuesFromTag(field.Tag.Get(configTag))
+	value, ok := options[defaultOption]
+	if !ok {
+		return nil, false, nil
+	}
+
+	result := reflect.New(field.Type)
+	err := s.decodeValue(s.parseString(value, field.Type), result.Interface())
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid default value for field %s: %w", field.Name, err)
+	}
+
+	return result.Elem().Interface(), true, nil
+}
+
+// applyElementDefaults sets the defaults of the fields of structs inside slices and maps, in place.
+// They are not part of the defaults layer, as the elements only exist once the sources are merged, so they are filled in afterwards.
+// The keys that a source set are kept.
+func (s *Service) applyElementDefaults(value any, t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			if isValueType(t) {
+				return nil
+			}
+
+			for key, nested := range typed {
+				field, ok := s.lookupField(t, key)
+				if !ok {
+					continue
+				}
+
+				err := s.applyElementDefaults(nested, field.Type)
+				if err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			for _, nested := range typed {
+				err := s.fillElementDefaults(nested, t.Elem())
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+
+		for _, item := range typed {
+			err := s.fillElementDefaults(item, t.Elem())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fillElementDefaults sets the defaults of the struct type t in a single element of a slice or map, and of the elements nested inside it.
+func (s *Service) fillElementDefaults(element any, t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if elementMap, ok := element.(map[string]any); ok && t.Kind() == reflect.Struct && !isValueType(t) {
+		defaults, err := s.canonicalSource(s.defaultsFromType(t), t)
+		if err != nil {
+			return err
+		}
+		s.parseStrings(defaults, t)
+
+		fillMissing(elementMap, defaults)
+	}
+
+	return s.applyElementDefaults(element, t)
+}
+
+// fillMissing copies the keys of src that are not set in dst into it, recursing into the maps they both have.
+func fillMissing(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		existingMap, existingOk := existing.(map[string]any)
+		valueMap, valueOk := value.(map[string]any)
+		if existingOk && valueOk {
+			fillMissing(existingMap, valueMap)
+		}
+	}
+}
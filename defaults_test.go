@@ -0,0 +1,115 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Defaults(t *testing.T) {
+	type testStruct struct {
+		Host     string `config:"host,default=localhost"`
+		Port     int    `config:"port,default=8080"`
+		Debug    bool   `config:"debug,default=true"`
+		Database struct {
+			URL string `config:"url,default=postgres://localhost/app?sslmode=disable"`
+		} `config:"database"`
+	}
+
+	t.Run("should use the defaults as the lowest precedence layer", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("port: 9090"), 0644))
+
+		var config testStruct
+		err := New(WithSourceFiles(path)).Unmarshal(&config)
+		require.NoError(t, err)
+
+		require.Equal(t, "localhost", config.Host)
+		require.Equal(t, 9090, config.Port)
+		require.True(t, config.Debug)
+		require.Equal(t, "postgres://localhost/app?sslmode=disable", config.Database.URL)
+	})
+
+	t.Run("should override the defaults with keys that are spelled differently", func(t *testing.T) {
+		type spellingStruct struct {
+			Port     int `config:"port,default=8080"`
+			MaxConns int `config:",default=5"`
+			Database struct {
+				Host string `config:",default=localhost"`
+			}
+		}
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("Port: 9090\nmaxConns: 50\nDATABASE:\n  host: db.internal\n"), 0644))
+
+		// The decoder picks a random key when several of them match a field, so a single pass could pass by chance
+		for i := 0; i < 20; i++ {
+			var config spellingStruct
+			err := New(WithSourceFiles(path)).Unmarshal(&config)
+			require.NoError(t, err)
+
+			require.Equal(t, 9090, config.Port)
+			require.Equal(t, 50, config.MaxConns)
+			require.Equal(t, "db.internal", config.Database.Host)
+		}
+	})
+
+	t.Run("should apply the defaults to the elements of slices and maps", func(t *testing.T) {
+		type server struct {
+			Host string
+			Port int `config:"port,default=80"`
+		}
+
+		type elementsStruct struct {
+			Servers []server
+			Regions map[string]struct {
+				Servers []server
+			}
+		}
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("servers:\n  - host: a\n  - host: b\n    port: 81\nregions:\n  eu:\n    servers:\n      - host: c\n"), 0644))
+
+		var config elementsStruct
+		err := New(WithSourceFiles(path)).Unmarshal(&config)
+		require.NoError(t, err)
+
+		require.Equal(t, []server{{Host: "a", Port: 80}, {Host: "b", Port: 81}}, config.Servers)
+		require.Equal(t, []server{{Host: "c", Port: 80}}, config.Regions["eu"].Servers)
+	})
+
+	t.Run("should split the defaults of slices and maps with the list separator", func(t *testing.T) {
+		type listStruct struct {
+			Hosts  []string       `config:"hosts,default=a;b"`
+			Limits map[string]int `config:"limits,default=cpu=2;memory=512"`
+		}
+
+		var config listStruct
+		err := New(WithEnvironmentVariablesListSeparator(";")).Unmarshal(&config)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"a", "b"}, config.Hosts)
+		require.Equal(t, map[string]int{"cpu": 2, "memory": 512}, config.Limits)
+	})
+
+	t.Run("should add typed defaults to the schema", func(t *testing.T) {
+		schema, err := New().schemaFromType(reflect.TypeOf(testStruct{}), false)
+		require.NoError(t, err)
+
+		require.Equal(t, "localhost", schema.Properties["host"].Default)
+		require.Equal(t, 8080, schema.Properties["port"].Default)
+		require.Equal(t, true, schema.Properties["debug"].Default)
+	})
+
+	t.Run("should return an error for a default that does not match the type", func(t *testing.T) {
+		type invalidStruct struct {
+			Port int `config:"port,default=abc"`
+		}
+
+		_, err := New().schemaFromType(reflect.TypeOf(invalidStruct{}), false)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,327 @@
+package confuse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// dotenvParser parses the contents of a .env file.
+// It supports comments, blank lines, export prefixes, single and double quoted values spanning multiple lines,
+// escape sequences in double quoted values, inline comments, and ${VAR} expansion.
+type dotenvParser struct {
+	src  string
+	pos  int
+	vars map[string]string
+}
+
+// parseDotenv parses the contents of a .env file into a list of NAME=value entries, in the order they are defined.
+// Variables are expanded from the ones defined earlier in the file, falling back to the environment.
+func parseDotenv(data []byte) ([]string, error) {
+	p := &dotenvParser{
+		src:  string(data),
+		vars: make(map[string]string),
+	}
+
+	var entries []string
+	for {
+		p.skipBlankLinesAndComments()
+		if p.eof() {
+			return entries, nil
+		}
+
+		name, value, err := p.parseEntry()
+		if err != nil {
+			return nil, err
+		}
+
+		p.vars[name] = value
+		entries = append(entries, name+"="+value)
+	}
+}
+
+func (p *dotenvParser) parseEntry() (string, string, error) {
+	if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.pos
+	for !p.eof() && isDotenvNameChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+
+	name := p.src[start:p.pos]
+	if name == "" {
+		return "", "", p.errorAt(start, "expected a variable name")
+	}
+
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return "", "", p.errorAt(p.pos, fmt.Sprintf("expected = after %s", name))
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var value string
+	var err error
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		value, err = p.parseSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, value, p.finishLine()
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	end := strings.IndexByte(p.src[p.pos:], '\'')
+	if end == -1 {
+		return "", p.errorAt(start, "unterminated single quoted value")
+	}
+
+	value := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+
+	return value, nil
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorAt(start, "unterminated double quoted value")
+		}
+
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorAt(start, "unterminated double quoted value")
+			}
+
+			switch escaped := p.peek(); escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\', '$':
+				value.WriteByte(escaped)
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(escaped)
+			}
+			p.pos++
+		case '$':
+			expanded, err := p.parseExpansion()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(expanded)
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquoted() (string, error) {
+	var value strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+
+		// A # only starts a comment at the start of the value or after whitespace, so values like a#b are kept
+		if c == '#' && (value.Len() == 0 || isDotenvSpace(p.src[p.pos-1])) {
+			break
+		}
+
+		if c == '$' {
+			expanded, err := p.parseExpansion()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(expanded)
+			continue
+		}
+
+		value.WriteByte(c)
+		p.pos++
+	}
+
+	return strings.TrimRight(value.String(), " \t\r"), nil
+}
+
+// parseExpansion parses a $VAR, ${VAR} or ${VAR:-default} expansion at the current position and returns its value.
+// A $ that is not followed by a variable name is kept as it is.
+func (p *dotenvParser) parseExpansion() (string, error) {
+	start := p.pos
+	p.pos++
+
+	if !p.eof() && p.peek() == '{' {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end == -1 {
+			return "", p.errorAt(start, "unterminated variable expansion")
+		}
+
+		expression := p.src[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+
+		name, fallback, hasFallback := strings.Cut(expression, ":-")
+		if value, ok := p.lookup(name); ok && (value != "" || !hasFallback) {
+			return value, nil
+		}
+
+		return fallback, nil
+	}
+
+	nameStart := p.pos
+	for !p.eof() && isVariableNameChar(p.peek(), p.pos == nameStart) {
+		p.pos++
+	}
+
+	if p.pos == nameStart {
+		return "$", nil
+	}
+
+	value, _ := p.lookup(p.src[nameStart:p.pos])
+
+	return value, nil
+}
+
+func (p *dotenvParser) lookup(name string) (string, bool) {
+	if value, ok := p.vars[name]; ok {
+		return value, true
+	}
+
+	return os.LookupEnv(name)
+}
+
+// finishLine makes sure nothing but whitespace and a comment follows a value.
+func (p *dotenvParser) finishLine() error {
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '#' {
+		p.skipToEndOfLine()
+	}
+
+	if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+		return p.errorAt(p.pos, fmt.Sprintf("unexpected character %q after value", p.peek()))
+	}
+
+	return nil
+}
+
+func (p *dotenvParser) skipBlankLinesAndComments() {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case isDotenvSpace(c) || c == '\n' || c == '\r':
+			p.pos++
+		case c == '#':
+			p.skipToEndOfLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && isDotenvSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipToEndOfLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) errorAt(offset int, message string) error {
+	line, column := lineAndColumn([]byte(p.src), int64(offset))
+
+	return &ParseError{Line: line, Column: column, Err: errors.New(message)}
+}
+
+func isDotenvSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isDotenvNameChar reports whether the character can be part of the name of a variable being defined.
+// Dots and dashes are allowed after the first character, for names that are not valid shell variables but are still used in .env files.
+func isDotenvNameChar(c byte, first bool) bool {
+	if c == '.' || c == '-' {
+		return !first
+	}
+
+	return isVariableNameChar(c, first)
+}
+
+// isVariableNameChar reports whether the character can be part of the name of a variable in a $VAR expansion.
+func isVariableNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
+
+// quoteDotenv quotes the value for a .env file, unless it only contains characters that don't need quoting.
+func quoteDotenv(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return r > unicode.MaxASCII || !isDotenvNameChar(byte(r), false) && !strings.ContainsRune("/:@+,=", r)
+	}) == -1 {
+		return value
+	}
+
+	var result strings.Builder
+	result.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\', '"', '$':
+			result.WriteByte('\\')
+			result.WriteRune(r)
+		case '\n':
+			result.WriteString(`\n`)
+		case '\r':
+			result.WriteString(`\r`)
+		case '\t':
+			result.WriteString(`\t`)
+		default:
+			result.WriteRune(r)
+		}
+	}
+	result.WriteByte('"')
+
+	return result.String()
+}
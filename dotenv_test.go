@@ -0,0 +1,67 @@
+package confuse
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Run("parses the dotenv syntax", func(t *testing.T) {
+		t.Setenv("DOTENV_TEST_HOME", "/home/test")
+
+		entries, err := parseDotenv([]byte(`# A comment
+
+export NAME=value
+SPACED = spaced value   # inline comment
+HASH=a#b
+URL=postgres://user@host/db?sslmode=disable
+SINGLE='single ${NAME} # not a comment'
+DOUBLE="double \"quoted\"\tvalue\n"
+MULTI="first
+second"
+EXPANDED=${NAME}-$NAME-${DOTENV_TEST_HOME}
+FALLBACK=${DOTENV_TEST_MISSING:-fallback}
+EMPTY=
+`))
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"NAME=value",
+			"SPACED=spaced value",
+			"HASH=a#b",
+			"URL=postgres://user@host/db?sslmode=disable",
+			"SINGLE=single ${NAME} # not a comment",
+			"DOUBLE=double \"quoted\"\tvalue\n",
+			"MULTI=first\nsecond",
+			"EXPANDED=value-value-/home/test",
+			"FALLBACK=fallback",
+			"EMPTY=",
+		}, entries)
+	})
+
+	errorCases := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{"missing equals", "A=1\nB 2\n", 2, 3},
+		{"missing name", "A=1\n=2\n", 2, 1},
+		{"unterminated double quote", "A=1\nB=\"abc\n", 2, 3},
+		{"unterminated single quote", "A='abc\n", 1, 3},
+		{"unterminated expansion", "A=${B\n", 1, 3},
+		{"characters after a quoted value", "A=\"abc\" def\n", 1, 9},
+	}
+
+	for _, errorCase := range errorCases {
+		t.Run("returns a line numbered error for "+errorCase.name, func(t *testing.T) {
+			_, err := parseDotenv([]byte(errorCase.content))
+
+			var parseError *ParseError
+			require.True(t, errors.As(err, &parseError))
+			require.Equal(t, errorCase.line, parseError.Line)
+			require.Equal(t, errorCase.column, parseError.Column)
+		})
+	}
+}
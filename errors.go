@@ -0,0 +1,90 @@
+package confuse
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedFormat is returned, wrapped in a FileError, when a source file has an extension that is not supported.
+var ErrUnsupportedFormat = errors.New("unsupported configuration file format")
+
+// FileError is returned when a source file cannot be read.
+type FileError struct {
+	// File is the path of the source file.
+	File string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FileError) Error() string {
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is returned when a source file cannot be parsed.
+type ParseError struct {
+	// File is the path of the source file.
+	File string
+
+	// Line is the line the error occurred on, starting at 1, or 0 if it is not known.
+	Line int
+
+	// Column is the column the error occurred on, starting at 1, or 0 if it is not known.
+	Column int
+
+	// Err is the underlying error from the parser.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	position := e.File
+	if e.Line > 0 {
+		position += fmt.Sprintf(":%d", e.Line)
+		if e.Column > 0 {
+			position += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+
+	return position + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// LoaderError is returned when one of the SourceLoaders fails.
+type LoaderError struct {
+	// Index is the index of the loader in SourceLoaders.
+	Index int
+
+	// Err is the error returned by the loader.
+	Err error
+}
+
+func (e *LoaderError) Error() string {
+	return fmt.Sprintf("loader %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *LoaderError) Unwrap() error {
+	return e.Err
+}
+
+// lineAndColumn converts a byte offset in the data to a line and column, both starting at 1.
+func lineAndColumn(data []byte, offset int64) (line int, column int) {
+	line, column = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			column = 1
+			continue
+		}
+
+		column++
+	}
+
+	return line, column
+}
@@ -0,0 +1,74 @@
+package confuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("should return a FileError for a missing file", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.yaml")
+		_, err := New().unmarshalFile(SourceFile{Path: path})
+
+		var fileError *FileError
+		require.True(t, errors.As(err, &fileError))
+		require.Equal(t, path, fileError.File)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should return ErrUnsupportedFormat for an unknown extension", func(t *testing.T) {
+		path := filepath.Join(dir, "config.ini")
+		require.NoError(t, os.WriteFile(path, []byte("a=b"), 0644))
+
+		_, err := New().unmarshalFile(SourceFile{Path: path})
+		require.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+
+	parseErrors := []struct {
+		name    string
+		file    string
+		content string
+		line    int
+		column  int
+	}{
+		{"json", "config.json", "{\n  \"a\": 1,\n  \"b\" 2\n}", 3, 7},
+		{"yaml", "config.yaml", "a: 1\n  b: 2\n", 2, 0},
+		{"toml", "config.toml", "a = 1\nb = = 2\n", 2, 5},
+	}
+
+	for _, parseErrorCase := range parseErrors {
+		t.Run("should return a ParseError with the position for "+parseErrorCase.name, func(t *testing.T) {
+			path := filepath.Join(dir, parseErrorCase.file)
+			require.NoError(t, os.WriteFile(path, []byte(parseErrorCase.content), 0644))
+
+			_, err := New().unmarshalFile(SourceFile{Path: path})
+
+			var parseError *ParseError
+			require.True(t, errors.As(err, &parseError))
+			require.Equal(t, path, parseError.File)
+			require.Equal(t, parseErrorCase.line, parseError.Line)
+			require.Equal(t, parseErrorCase.column, parseError.Column)
+		})
+	}
+
+	t.Run("should return a LoaderError with the index of the loader", func(t *testing.T) {
+		loaderErr := errors.New("loader failed")
+
+		var config struct{}
+		err := New(WithSourceLoaders(
+			func() (map[string]any, error) { return nil, nil },
+			func() (map[string]any, error) { return nil, loaderErr },
+		)).Unmarshal(&config)
+
+		var loaderError *LoaderError
+		require.True(t, errors.As(err, &loaderError))
+		require.Equal(t, 1, loaderError.Index)
+		require.ErrorIs(t, err, loaderErr)
+	})
+}
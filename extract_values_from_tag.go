@@ -8,8 +8,8 @@ func extractValuesFromTag(tag string) (name string, options map[string]string) {
 	options = make(map[string]string)
 
 	for _, option := range split[1:] {
-		optionSplit := strings.Split(option, "=")
-		options[optionSplit[0]] = optionSplit[1]
+		key, value, _ := strings.Cut(option, "=")
+		options[key] = value
 	}
 
 	return name, options
@@ -0,0 +1,157 @@
+package confuse
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const usageOption = "usage"
+
+// flagValue is the flag.Value registered for every leaf field of the config struct.
+// It keeps the raw strings, which are converted to the type of the field when decoding, like any other source.
+type flagValue struct {
+	isBool  bool
+	isSlice bool
+	values  []string
+	set     bool
+}
+
+func (v *flagValue) String() string {
+	return strings.Join(v.values, ",")
+}
+
+func (v *flagValue) Set(value string) error {
+	// The first value replaces the default, and slices collect every repeated flag after that
+	if !v.set || !v.isSlice {
+		v.values = nil
+	}
+
+	v.values = append(v.values, value)
+	v.set = true
+
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// value returns the value to merge into the configuration.
+func (v *flagValue) value() any {
+	if !v.isSlice {
+		return strings.Join(v.values, ",")
+	}
+
+	result := make([]any, 0, len(v.values))
+	for _, value := range v.values {
+		result = append(result, value)
+	}
+
+	return result
+}
+
+// flagName returns the name of the flag for the field, which is its path with each key in kebab case, e.g. database-config.host.
+func flagName(field configField) string {
+	names := make([]string, 0, len(field.Path))
+	for _, key := range field.Path {
+		names = append(names, strcase.ToKebab(key))
+	}
+
+	return strings.Join(names, ".")
+}
+
+// flagUsage returns the help text for the flag of the field, from the usage or desc option of the config tag and the validate tag.
+func flagUsage(field configField) string {
+	usage := field.Options[usageOption]
+	if usage == "" {
+		usage = field.Options[descriptionOption]
+	}
+	if validation := field.Field.Tag.Get("validate"); validation != "" {
+		if usage != "" {
+			usage += " "
+		}
+		usage += "(validate: " + validation + ")"
+	}
+
+	return usage
+}
+
+// isFlagType reports whether the type can be set with a flag, which is any scalar value or a slice of them.
+func isFlagType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		return elem.Kind() != reflect.Slice && elem.Kind() != reflect.Array && isFlagType(elem)
+	case reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	case reflect.Struct:
+		return isValueType(t)
+	default:
+		return true
+	}
+}
+
+// parseFlags registers a flag for every leaf field of the config struct type t on FlagSet, and parses FlagArgs if it has not been parsed yet.
+// It returns the values of the flags that were explicitly set, and the name of the flag that set each value, keyed by the dot separated path of the value.
+func (s *Service) parseFlags(t reflect.Type) (map[string]any, map[string]string, error) {
+	fields := make(map[string]configField)
+	for _, field := range s.leafFields(t, nil) {
+		if !isFlagType(field.Field.Type) {
+			continue
+		}
+
+		name := flagName(field)
+		fields[name] = field
+		if s.FlagSet.Lookup(name) != nil {
+			continue
+		}
+
+		fieldType := field.Field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		value := &flagValue{
+			isBool:  fieldType.Kind() == reflect.Bool,
+			isSlice: fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array,
+		}
+		if defaultValue, ok := field.Options[defaultOption]; ok {
+			value.values = []string{defaultValue}
+		}
+
+		s.FlagSet.Var(value, name, flagUsage(field))
+	}
+
+	if !s.FlagSet.Parsed() {
+		err := s.FlagSet.Parse(s.FlagArgs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result := make(map[string]any)
+	names := make(map[string]string)
+	s.FlagSet.Visit(func(f *flag.Flag) {
+		field, ok := fields[f.Name]
+		if !ok {
+			return
+		}
+
+		var value any = f.Value.String()
+		if configFlagValue, ok := f.Value.(*flagValue); ok {
+			value = configFlagValue.value()
+		}
+
+		setPath(result, field.Path, value)
+		names[strings.Join(field.Path, ".")] = f.Name
+	})
+
+	return result, names, nil
+}
@@ -0,0 +1,89 @@
+package confuse
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Flags(t *testing.T) {
+	type testStruct struct {
+		Debug          bool
+		Tags           []string
+		DatabaseConfig struct {
+			Host string `config:"host,usage=The database host" validate:"required"`
+			Port int    `config:"port,default=5432"`
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("database_config:\n  host: file\n  port: 5433\n"), 0644))
+
+	newFlagSet := func() *flag.FlagSet {
+		flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		return flagSet
+	}
+
+	t.Run("should override every other source with the flags that are set", func(t *testing.T) {
+		t.Setenv("FLAGS_TEST_DATABASE_CONFIG__HOST", "env")
+
+		args := []string{"--debug", "--tags", "a", "--tags", "b", "--database-config.host", "flag"}
+		s := New(
+			WithSourceFiles(path),
+			WithEnvironmentVariables(true),
+			WithEnvironmentVariablesPrefix("FLAGS_TEST_"),
+			WithFlags(newFlagSet(), args),
+		)
+
+		var config testStruct
+		err := s.Unmarshal(&config)
+		require.NoError(t, err)
+
+		require.True(t, config.Debug)
+		require.Equal(t, []string{"a", "b"}, config.Tags)
+		require.Equal(t, "flag", config.DatabaseConfig.Host)
+		require.Equal(t, 5433, config.DatabaseConfig.Port)
+	})
+
+	t.Run("should override keys that are spelled differently in the files", func(t *testing.T) {
+		pascalPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(pascalPath, []byte("DatabaseConfig:\n  host: file\n"), 0644))
+
+		s := New(WithSourceFiles(pascalPath), WithFlags(newFlagSet(), []string{"--database-config.host", "flag"}))
+
+		var config testStruct
+		provenance, err := s.Explain(&config)
+		require.NoError(t, err)
+
+		require.Equal(t, "flag", config.DatabaseConfig.Host)
+		require.Equal(t, Origin{Kind: OriginFlag, Flag: "database-config.host"}, provenance["database_config.host"].Origin)
+	})
+
+	t.Run("should register the flags with help text and defaults", func(t *testing.T) {
+		flagSet := newFlagSet()
+
+		var config testStruct
+		err := New(WithFlags(flagSet, nil)).Unmarshal(&config)
+		require.NoError(t, err)
+
+		host := flagSet.Lookup("database-config.host")
+		require.NotNil(t, host)
+		require.Equal(t, "The database host (validate: required)", host.Usage)
+
+		port := flagSet.Lookup("database-config.port")
+		require.NotNil(t, port)
+		require.Equal(t, "5432", port.DefValue)
+		require.Equal(t, 5432, config.DatabaseConfig.Port)
+	})
+
+	t.Run("should return an error for an unknown flag", func(t *testing.T) {
+		var config testStruct
+		err := New(WithFlags(newFlagSet(), []string{"--unknown"})).Unmarshal(&config)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,82 @@
+package confuse
+
+import (
+	"strings"
+	"sync"
+)
+
+// Decoder decodes the contents of a source file into a map.
+type Decoder interface {
+	Decode(data []byte) (map[string]any, error)
+}
+
+// DecoderFunc is an adapter to allow the use of ordinary functions as a Decoder.
+type DecoderFunc func(data []byte) (map[string]any, error)
+
+// Decode calls f(data).
+func (f DecoderFunc) Decode(data []byte) (map[string]any, error) {
+	return f(data)
+}
+
+// Encoder encodes a map into the contents of a file.
+// Decoders can implement it to support rendering the configuration in their format.
+type Encoder interface {
+	Encode(values map[string]any) ([]byte, error)
+}
+
+// serviceDecoder is implemented by decoders that depend on the settings of the Service using them.
+type serviceDecoder interface {
+	forService(s *Service) Decoder
+}
+
+var (
+	formatsMutex sync.RWMutex
+	formats      = map[string]Decoder{
+		".json": jsonDecoder{},
+		".yaml": yamlDecoder{},
+		".yml":  yamlDecoder{},
+		".toml": tomlDecoder{},
+		".env":  envDecoder{},
+	}
+)
+
+// RegisterFormat registers the decoder to use for source files with the given extension, for every Service.
+// It replaces any decoder that is already registered for the extension, including the built-in ones.
+// Decoders set on a Service with WithFormat take precedence over the registered ones.
+func RegisterFormat(ext string, decoder Decoder) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+
+	formats[normalizeExtension(ext)] = decoder
+}
+
+// decoderFor returns the decoder to use for the extension, or false if the format is not supported.
+func (s *Service) decoderFor(ext string) (Decoder, bool) {
+	ext = normalizeExtension(ext)
+
+	decoder, ok := s.Formats[ext]
+	if !ok {
+		formatsMutex.RLock()
+		decoder, ok = formats[ext]
+		formatsMutex.RUnlock()
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	if serviceDecoder, ok := decoder.(serviceDecoder); ok {
+		decoder = serviceDecoder.forService(s)
+	}
+
+	return decoder, true
+}
+
+// normalizeExtension makes sure the extension starts with a dot and is lowercase, so ".YAML", "yaml" and ".yaml" are the same format.
+func normalizeExtension(ext string) string {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	return strings.ToLower(ext)
+}
@@ -0,0 +1,58 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Formats(t *testing.T) {
+	type testStruct struct {
+		Name string
+	}
+
+	keyValueDecoder := func(prefix string) Decoder {
+		return DecoderFunc(func(data []byte) (map[string]any, error) {
+			key, value, _ := strings.Cut(strings.TrimSpace(string(data)), ":")
+			return map[string]any{key: prefix + value}, nil
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.kv")
+	require.NoError(t, os.WriteFile(path, []byte("name:value"), 0644))
+
+	t.Run("should return an error for an unregistered format", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(path)).Unmarshal(&config)
+		require.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+
+	t.Run("should use the decoder set with WithFormat", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(path), WithFormat("kv", keyValueDecoder("service-"))).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "service-value", config.Name)
+	})
+
+	t.Run("should use the decoder registered with RegisterFormat", func(t *testing.T) {
+		RegisterFormat(".kv", keyValueDecoder("global-"))
+		t.Cleanup(func() {
+			formatsMutex.Lock()
+			defer formatsMutex.Unlock()
+
+			delete(formats, ".kv")
+		})
+
+		var config testStruct
+		err := New(WithSourceFiles(path)).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "global-value", config.Name)
+
+		err = New(WithSourceFiles(path), WithFormat(".KV", keyValueDecoder("service-"))).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "service-value", config.Name)
+	})
+}
@@ -0,0 +1,280 @@
+package confuse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const envReferencePrefix = "env:"
+
+// interpolator resolves the ${path.to.key} and ${env:NAME} references in the string values of the merged configuration.
+type interpolator struct {
+	root     map[string]any
+	resolved map[string]bool
+	visiting []string
+
+	// keyName names the path of a key in errors
+	keyName func(path []string) string
+}
+
+// interpolate replaces the references in the string values of the merged configuration that are decoded into objType, in place.
+// Keys that do not correspond to a field, such as unrelated environment variables, are left as they are unless another value references them.
+// ${path.to.key} is replaced with the value of another key, ${env:NAME} with the value of an environment variable, and $${ is an escaped ${.
+// A string that is only a reference to another key takes the value of that key as it is, so it keeps its type.
+// The keys in errors are named after the fields of objType.
+func (s *Service) interpolate(fullMap map[string]any, objType reflect.Type) error {
+	i := &interpolator{
+		root:     fullMap,
+		resolved: make(map[string]bool),
+		keyName: func(path []string) string {
+			return strings.Join(s.canonicalPath(objType, path), ".")
+		},
+	}
+
+	return i.walk(s, fullMap, objType, nil)
+}
+
+// walk resolves the string values under the value decoded into the type t, which is nil when it is not known.
+func (i *interpolator) walk(s *Service, value any, t reflect.Type, path []string) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if t != nil && t.Kind() == reflect.Struct && !isValueType(t) {
+				if _, ok := s.lookupField(t, key); !ok {
+					continue
+				}
+			}
+
+			nestedType, _ := s.keyType(t, key)
+			err := i.walk(s, typed[key], nestedType, append(append([]string{}, path...), key))
+			if err != nil {
+				return err
+			}
+		}
+	case []any:
+		for index, item := range typed {
+			err := i.walk(s, item, elemType(t), append(append([]string{}, path...), strconv.Itoa(index)))
+			if err != nil {
+				return err
+			}
+		}
+	case string:
+		_, err := i.resolve(path)
+		return err
+	}
+
+	return nil
+}
+
+// resolve returns the value at the path with its references replaced, replacing it in the configuration.
+// The values are tracked by the keys they are stored under, as references can spell the same key in different ways.
+func (i *interpolator) resolve(reference []string) (any, error) {
+	value, path, ok := getPath(i.root, reference)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", strings.Join(reference, "."))
+	}
+
+	key := strings.Join(path, ".")
+	str, ok := value.(string)
+	if !ok || i.resolved[key] {
+		return value, nil
+	}
+
+	for index, visiting := range i.visiting {
+		if visiting == key {
+			var cycle []string
+			for _, cycleKey := range append(i.visiting[index:], key) {
+				cycle = append(cycle, i.keyName(strings.Split(cycleKey, ".")))
+			}
+
+			return nil, fmt.Errorf("reference cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	i.visiting = append(i.visiting, key)
+	result, err := i.interpolateString(str)
+	i.visiting = i.visiting[:len(i.visiting)-1]
+	if err != nil {
+		// The error is reported for the key closest to the broken reference
+		var interpolationError *InterpolationError
+		if errors.As(err, &interpolationError) {
+			return nil, err
+		}
+
+		return nil, &InterpolationError{Key: i.keyName(path), Err: err}
+	}
+
+	setPathValue(i.root, path, result)
+	i.resolved[key] = true
+
+	return result, nil
+}
+
+func (i *interpolator) interpolateString(str string) (any, error) {
+	// A value that is a single reference takes the referenced value with its type
+	if strings.HasPrefix(str, "${") && strings.Index(str, "}") == len(str)-1 && !strings.HasPrefix(str[2:], envReferencePrefix) {
+		return i.resolve(splitReference(str[2 : len(str)-1]))
+	}
+
+	var result strings.Builder
+	for {
+		start := strings.Index(str, "${")
+		if start == -1 {
+			result.WriteString(str)
+			return result.String(), nil
+		}
+
+		// $${ is an escaped ${
+		if start > 0 && str[start-1] == '$' {
+			result.WriteString(str[:start-1])
+			result.WriteString("${")
+			str = str[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(str[start:], '}')
+		if end == -1 {
+			return nil, errors.New("unterminated reference")
+		}
+
+		result.WriteString(str[:start])
+
+		reference := str[start+2 : start+end]
+		if name, ok := strings.CutPrefix(reference, envReferencePrefix); ok {
+			result.WriteString(os.Getenv(name))
+		} else {
+			value, err := i.resolve(splitReference(reference))
+			if err != nil {
+				return nil, err
+			}
+
+			result.WriteString(fmt.Sprint(value))
+		}
+
+		str = str[start+end+1:]
+	}
+}
+
+// InterpolationError is returned when a reference in the value of a key cannot be resolved.
+type InterpolationError struct {
+	// Key is the dot separated path of the key whose value contains the reference.
+	Key string
+
+	// Err is the reason the reference cannot be resolved.
+	Err error
+}
+
+func (e *InterpolationError) Error() string {
+	return "interpolating " + e.Key + ": " + e.Err.Error()
+}
+
+func (e *InterpolationError) Unwrap() error {
+	return e.Err
+}
+
+func splitReference(reference string) []string {
+	return strings.Split(strings.TrimSpace(reference), ".")
+}
+
+// getPath returns the value at the path in the nested maps and slices, and the path of the keys it is stored under.
+// Map keys are matched the same way as when decoding, so references can use any casing of the key.
+func getPath(root any, path []string) (any, []string, bool) {
+	current := root
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		switch typed := current.(type) {
+		case map[string]any:
+			mapKey, ok := findKey(typed, key)
+			if !ok {
+				return nil, nil, false
+			}
+
+			current = typed[mapKey]
+			keys = append(keys, mapKey)
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, nil, false
+			}
+
+			current = typed[index]
+			keys = append(keys, strconv.Itoa(index))
+		default:
+			return nil, nil, false
+		}
+	}
+
+	return current, keys, true
+}
+
+// setPathValue replaces the existing value at the path in the nested maps and slices.
+func setPathValue(root any, path []string, value any) {
+	current := root
+	for i, key := range path {
+		last := i == len(path)-1
+		switch typed := current.(type) {
+		case map[string]any:
+			mapKey, ok := findKey(typed, key)
+			if !ok {
+				return
+			}
+
+			if last {
+				typed[mapKey] = value
+				return
+			}
+
+			current = typed[mapKey]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(typed) {
+				return
+			}
+
+			if last {
+				typed[index] = value
+				return
+			}
+
+			current = typed[index]
+		default:
+			return
+		}
+	}
+}
+
+// findKey returns the key of the map that matches the name, preferring an exact match.
+func findKey(m map[string]any, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if compareName(key, name) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
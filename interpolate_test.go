@@ -0,0 +1,113 @@
+package confuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Interpolation(t *testing.T) {
+	type testStruct struct {
+		Database struct {
+			Host string
+			Port int
+		}
+		URL         string
+		BackupPort  int
+		Home        string
+		Template    string
+		Hosts       []string
+		PrimaryHost string
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	override := filepath.Join(dir, "override.yaml")
+	require.NoError(t, os.WriteFile(base, []byte(`database:
+  host: localhost
+  port: 5432
+url: "postgres://${database.host}:${database.port}/app"
+backup_port: "${database.port}"
+home: "${env:INTERPOLATION_TEST_HOME}/app"
+template: "$${database.host} is ${Database.Host}"
+hosts: ["${database.host}", "replica"]
+primary_host: "${hosts.0}"
+`), 0644))
+	require.NoError(t, os.WriteFile(override, []byte("database:\n  host: db.internal\n"), 0644))
+
+	t.Run("should leave references as they are by default", func(t *testing.T) {
+		var config struct {
+			URL string
+		}
+		err := New(WithSourceFiles(base)).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "postgres://${database.host}:${database.port}/app", config.URL)
+	})
+
+	t.Run("should resolve references after every source is merged", func(t *testing.T) {
+		t.Setenv("INTERPOLATION_TEST_HOME", "/home/user")
+
+		var config testStruct
+		err := New(WithSourceFiles(base, override), WithInterpolation()).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "postgres://db.internal:5432/app", config.URL)
+		require.Equal(t, 5432, config.BackupPort)
+		require.Equal(t, "/home/user/app", config.Home)
+		require.Equal(t, "${database.host} is db.internal", config.Template)
+		require.Equal(t, []string{"db.internal", "replica"}, config.Hosts)
+		require.Equal(t, "db.internal", config.PrimaryHost)
+	})
+
+	t.Run("should resolve every value once however the references spell its key", func(t *testing.T) {
+		path := filepath.Join(dir, "escape.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("template: \"$${host} literal\"\ncopy: \"${Template}\"\nother: \"x ${template}\"\n"), 0644))
+
+		var config struct {
+			Template string
+			Copy     string
+			Other    string
+		}
+		err := New(WithSourceFiles(path), WithInterpolation()).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "${host} literal", config.Template)
+		require.Equal(t, "${host} literal", config.Copy)
+		require.Equal(t, "x ${host} literal", config.Other)
+	})
+
+	t.Run("should leave the keys that do not match a field as they are", func(t *testing.T) {
+		t.Setenv("INTERPOLATION_TEST_X", "${nope}")
+		t.Setenv("INTERPOLATION_TEST_URL", "${database.host}/app")
+
+		var config testStruct
+		err := New(WithSourceFiles(base), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("INTERPOLATION_TEST_"), WithInterpolation()).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, "localhost/app", config.URL)
+	})
+
+	t.Run("should fail on unknown keys and cycles", func(t *testing.T) {
+		for name, contents := range map[string]string{
+			"unknown": "url: \"${missing.key}\"\n",
+			"cycle":   "url: \"${template}\"\ntemplate: \"a ${url}\"\n",
+			"open":    "url: \"${database.host\"\n",
+		} {
+			contents := contents
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(dir, name+".yaml")
+				require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+
+				var config testStruct
+				err := New(WithSourceFiles(path), WithInterpolation()).Unmarshal(&config)
+
+				var interpolationError *InterpolationError
+				require.True(t, errors.As(err, &interpolationError), err)
+				require.Equal(t, "url", interpolationError.Key)
+				if name == "cycle" {
+					require.EqualError(t, err, "interpolating url: reference cycle: template -> url -> template")
+				}
+			})
+		}
+	})
+}
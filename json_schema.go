@@ -2,20 +2,23 @@ package confuse
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"reflect"
 )
 
-func (s *Service) generateSchemaFiles(result any) error {
+// generateSchemaFiles writes the JSON schema files for the config struct type t, if they are configured.
+func (s *Service) generateSchemaFiles(t reflect.Type) error {
 	if s.ExactOutputJSONSchema != "" {
-		err := s.generateJSONSchemaFile(result, false, s.ExactOutputJSONSchema)
+		err := s.generateJSONSchemaFile(t, false, s.ExactOutputJSONSchema)
 		if err != nil {
 			return err
 		}
 	}
 
 	if s.FuzzyOutputJSONSchema != "" {
-		err := s.generateJSONSchemaFile(result, true, s.FuzzyOutputJSONSchema)
+		err := s.generateJSONSchemaFile(t, true, s.FuzzyOutputJSONSchema)
 		if err != nil {
 			return err
 		}
@@ -24,31 +27,105 @@ func (s *Service) generateSchemaFiles(result any) error {
 	return nil
 }
 
-func (s *Service) generateJSONSchemaFile(result any, fuzzy bool, outputFilePath string) error {
-	schema, err := s.schemaFromType(reflect.TypeOf(result), fuzzy)
+// GenerateSchema returns the exact JSON schema of the config struct v, without loading any configuration.
+// The options configure the schema the same way as for Unmarshal, e.g. WithValidation(true) to include the validate tags.
+func GenerateSchema(v any, opts ...Option) (JSONSchema, error) {
+	return New(opts...).GenerateSchema(v)
+}
+
+// WriteSchema writes the exact JSON schema of the config struct v to w, without loading any configuration.
+// It can be used in a go generate step to produce the schema files without any configuration files present.
+func WriteSchema(w io.Writer, v any, opts ...Option) error {
+	return New(opts...).WriteSchema(w, v)
+}
+
+// GenerateSchema returns the exact JSON schema of the config struct v, without loading any configuration.
+func (s *Service) GenerateSchema(v any) (JSONSchema, error) {
+	return s.jsonSchema(reflect.TypeOf(v), false)
+}
+
+// GenerateFuzzySchema returns the fuzzy JSON schema of the config struct v, where no field is required, without loading any configuration.
+func (s *Service) GenerateFuzzySchema(v any) (JSONSchema, error) {
+	return s.jsonSchema(reflect.TypeOf(v), true)
+}
+
+// WriteSchema writes the exact JSON schema of the config struct v to w, without loading any configuration.
+func (s *Service) WriteSchema(w io.Writer, v any) error {
+	return s.writeJSONSchema(w, reflect.TypeOf(v), false)
+}
+
+// WriteFuzzySchema writes the fuzzy JSON schema of the config struct v to w, where no field is required, without loading any configuration.
+func (s *Service) WriteFuzzySchema(w io.Writer, v any) error {
+	return s.writeJSONSchema(w, reflect.TypeOf(v), true)
+}
+
+func (s *Service) generateJSONSchemaFile(t reflect.Type, fuzzy bool, outputFilePath string) error {
+	bytes, err := s.marshalJSONSchema(t, fuzzy)
 	if err != nil {
 		return err
 	}
 
-	description := "This is a match of the config struct to the JSON Schema specification."
-	if fuzzy {
-		description = "This is a fuzzy match of the config struct to the JSON Schema specification."
+	err = os.WriteFile(outputFilePath, bytes, 0644)
+	if err != nil {
+		return err
 	}
 
-	jsonSchema := JSONSchema{
-		SchemaUri:   "https://json-schema.org/draft/2020-12/schema",
-		IDUri:       "http://example.com/schema.json", // TODO: Make this configurable if needed
-		Title:       "Confuse Configuration Schema",
-		Description: description,
-		Schema:      schema,
+	return nil
+}
+
+func (s *Service) writeJSONSchema(w io.Writer, t reflect.Type, fuzzy bool) error {
+	bytes, err := s.marshalJSONSchema(t, fuzzy)
+	if err != nil {
+		return err
 	}
 
-	bytes, err := json.MarshalIndent(jsonSchema, "", "  ")
+	_, err = w.Write(bytes)
 
-	err = os.WriteFile(outputFilePath, bytes, 0644)
+	return err
+}
+
+func (s *Service) marshalJSONSchema(t reflect.Type, fuzzy bool) ([]byte, error) {
+	jsonSchema, err := s.jsonSchema(t, fuzzy)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return json.MarshalIndent(jsonSchema, "", "  ")
+}
+
+// jsonSchema returns the JSON schema of the config struct type t, with the metadata of the Service.
+func (s *Service) jsonSchema(t reflect.Type, fuzzy bool) (JSONSchema, error) {
+	if t == nil {
+		return JSONSchema{}, errors.New("confuse: GenerateSchema requires a struct")
+	}
+
+	schema, err := s.schemaFromType(t, fuzzy)
+	if err != nil {
+		return JSONSchema{}, err
+	}
+
+	description := s.JSONSchemaDescription
+	if description == "" && fuzzy {
+		description = "This is a fuzzy match of the config struct to the JSON Schema specification."
+	} else if description == "" {
+		description = "This is a match of the config struct to the JSON Schema specification."
+	}
+
+	draft := s.JSONSchemaDraft
+	if draft == "" {
+		draft = JSONSchemaDraft2020_12
+	}
+
+	title := s.JSONSchemaTitle
+	if title == "" {
+		title = "Confuse Configuration Schema"
+	}
+
+	return JSONSchema{
+		SchemaUri:   string(draft),
+		IDUri:       s.JSONSchemaID,
+		Title:       title,
+		Description: description,
+		Schema:      schema,
+	}, nil
 }
@@ -0,0 +1,103 @@
+package confuse
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_JSONSchemaMetadata(t *testing.T) {
+	type testStruct struct {
+		Host string `validate:"required"`
+	}
+
+	readSchema := func(t *testing.T, opts ...Option) map[string]any {
+		path := filepath.Join(t.TempDir(), "schema.json")
+
+		var config testStruct
+		err := New(append([]Option{WithExactOutputJSONSchema(path)}, opts...)...).Unmarshal(&config)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var schema map[string]any
+		require.NoError(t, json.Unmarshal(data, &schema))
+
+		return schema
+	}
+
+	t.Run("should use the default metadata", func(t *testing.T) {
+		schema := readSchema(t)
+		require.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+		require.NotContains(t, schema, "$id")
+		require.Equal(t, "Confuse Configuration Schema", schema["title"])
+		require.Equal(t, "This is a match of the config struct to the JSON Schema specification.", schema["description"])
+	})
+
+	t.Run("should use the configured metadata", func(t *testing.T) {
+		schema := readSchema(t,
+			WithJSONSchemaID("https://example.org/schemas/app.json"),
+			WithJSONSchemaTitle("App"),
+			WithJSONSchemaDescription("The configuration of the app."),
+			WithJSONSchemaDraft(JSONSchemaDraft07),
+		)
+		require.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+		require.Equal(t, "https://example.org/schemas/app.json", schema["$id"])
+		require.Equal(t, "App", schema["title"])
+		require.Equal(t, "The configuration of the app.", schema["description"])
+		require.Equal(t, "object", schema["type"])
+	})
+}
+
+func TestGenerateSchema(t *testing.T) {
+	type testStruct struct {
+		Host string `validate:"required"`
+		Port int    `config:"port,default=8080"`
+	}
+
+	t.Run("should generate the schema without loading any configuration", func(t *testing.T) {
+		schema, err := GenerateSchema(testStruct{}, WithValidation(true), WithJSONSchemaTitle("App"), WithSourceFiles("missing.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, "App", schema.Title)
+		require.Equal(t, []string{"host"}, schema.Required)
+		require.Equal(t, "integer", schema.Properties["port"].Type)
+		require.Equal(t, 8080, schema.Properties["port"].Default)
+	})
+
+	t.Run("should generate the fuzzy schema", func(t *testing.T) {
+		schema, err := New(WithValidation(true)).GenerateFuzzySchema(&testStruct{})
+		require.NoError(t, err)
+		require.Empty(t, schema.Required)
+		require.Equal(t, "This is a fuzzy match of the config struct to the JSON Schema specification.", schema.Description)
+	})
+
+	t.Run("should write the same schema as the schema files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schema.json")
+		var config testStruct
+		require.NoError(t, New(WithValidation(false), WithExactOutputJSONSchema(path)).Unmarshal(&config))
+
+		expected, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var buffer bytes.Buffer
+		require.NoError(t, WriteSchema(&buffer, testStruct{}))
+		require.Equal(t, string(expected), buffer.String())
+
+		buffer.Reset()
+		require.NoError(t, New().WriteFuzzySchema(&buffer, testStruct{}))
+		require.Contains(t, buffer.String(), `"description": "This is a fuzzy match`)
+	})
+
+	t.Run("should fail on unsupported types", func(t *testing.T) {
+		_, err := GenerateSchema(nil)
+		require.Error(t, err)
+
+		err = WriteSchema(&bytes.Buffer{}, struct{ C chan int }{})
+		require.Error(t, err)
+	})
+}
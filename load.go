@@ -0,0 +1,43 @@
+package confuse
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Load creates a Service with the given options, and unmarshals the configuration into a new value of type T.
+// T must be a struct type, which is checked before any of the sources are loaded.
+// The JSON schema files, if any, are generated from T.
+func Load[T any](opts ...Option) (T, error) {
+	var result T
+
+	resultType := reflect.TypeOf((*T)(nil)).Elem()
+	if resultType.Kind() != reflect.Struct {
+		return result, fmt.Errorf("confuse: Load requires a struct type, got %s", resultType)
+	}
+
+	s := New(opts...)
+
+	err := s.load(&result)
+	if err != nil {
+		return result, err
+	}
+
+	err = s.generateSchemaFiles(resultType)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// MustLoad is like Load, but panics if the configuration cannot be loaded.
+// It is intended for use in main functions and package level variables.
+func MustLoad[T any](opts ...Option) T {
+	result, err := Load[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
@@ -0,0 +1,48 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	type testStruct struct {
+		Port int `validate:"required"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("port: 8080"), 0644))
+
+	t.Run("should load into a new value of the type", func(t *testing.T) {
+		config, err := Load[testStruct](WithSourceFiles(path))
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Port: 8080}, config)
+	})
+
+	t.Run("should return an error for a type that is not a struct", func(t *testing.T) {
+		_, err := Load[*testStruct](WithSourceFiles(path))
+		require.Error(t, err)
+
+		_, err = Load[map[string]any](WithSourceFiles(path))
+		require.Error(t, err)
+	})
+
+	t.Run("should generate the schema from the type", func(t *testing.T) {
+		schemaPath := filepath.Join(t.TempDir(), "schema.json")
+
+		_, err := Load[testStruct](WithSourceFiles(path), WithExactOutputJSONSchema(schemaPath))
+		require.NoError(t, err)
+		require.FileExists(t, schemaPath)
+	})
+
+	t.Run("should panic with MustLoad if loading fails", func(t *testing.T) {
+		require.Panics(t, func() {
+			MustLoad[testStruct](WithSourceFiles(path), WithSourceFiles("missing.yaml"))
+		})
+
+		require.Equal(t, testStruct{Port: 8080}, MustLoad[testStruct](WithSourceFiles(path)))
+	})
+}
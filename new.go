@@ -1,6 +1,8 @@
 package confuse
 
 import (
+	"time"
+
 	"dario.cat/mergo"
 	"github.com/go-playground/validator/v10"
 	"github.com/iancoleman/strcase"
@@ -8,8 +10,10 @@ import (
 
 func New(opts ...Option) *Service {
 	s := &Service{
-		EnvironmentVariablesSeparator: "__",
-		JSONSchemaKeyModifier:         strcase.ToSnake,
+		EnvironmentVariablesSeparator:     "__",
+		EnvironmentVariablesListSeparator: ",",
+		JSONSchemaKeyModifier:             strcase.ToSnake,
+		WatchInterval:                     time.Second,
 	}
 
 	for _, opt := range opts {
@@ -1,8 +1,14 @@
 package confuse
 
 import (
+	"flag"
+	"io/fs"
+	"strings"
+	"time"
+
 	"dario.cat/mergo"
 	"github.com/go-playground/validator/v10"
+	"github.com/mitchellh/mapstructure"
 )
 
 const configTag = "config"
@@ -14,12 +20,76 @@ type Option func(*Service)
 // The first file in the list takes the lowest precedence, and the last file takes the highest precedence.
 // E.g. if the same key is defined in both files, the value in the last file will be used.
 // Base -> Override 1 -> Override 2 -> Override 3
+// A path ending with a question mark, e.g. "config.local.yaml?", is optional and skipped if it doesn't exist.
 func WithSourceFiles(files ...string) Option {
 	return func(s *Service) {
+		// Once there are Sources, which are loaded after the SourceFiles, the files are added to them to keep the order of precedence
+		if len(s.Sources) > 0 {
+			for _, file := range files {
+				s.Sources = append(s.Sources, newSourceFile(file, nil))
+			}
+
+			return
+		}
+
 		s.SourceFiles = append(s.SourceFiles, files...)
 	}
 }
 
+// WithOptionalSourceFiles sets source files to read from that are skipped if they don't exist, such as local overrides that only exist on developer machines.
+// Errors reading or parsing the files when they do exist still fail the unmarshalling.
+// The files take precedence in the order they are added, the same as WithSourceFiles.
+func WithOptionalSourceFiles(files ...string) Option {
+	return func(s *Service) {
+		for _, file := range files {
+			source := newSourceFile(file, nil)
+			source.Optional = true
+			s.Sources = append(s.Sources, source)
+		}
+	}
+}
+
+// WithSourceGlob sets glob patterns, in the syntax of filepath.Match, that expand to source files to read from, e.g. "conf.d/*.yaml".
+// The matching files are read in lexical order, and take precedence in the order they are added, the same as WithSourceFiles.
+// A pattern that doesn't match any file is not an error.
+func WithSourceGlob(patterns ...string) Option {
+	return func(s *Service) {
+		for _, pattern := range patterns {
+			s.Sources = append(s.Sources, SourceFile{Path: pattern, Glob: true})
+		}
+	}
+}
+
+// WithSourceDirectory sets directories, such as /etc/myapp/conf.d, whose files are source files to read from.
+// Every file directly inside the directory with a supported format is read in lexical order, ignoring hidden files,
+// and the directories take precedence in the order they are added, the same as WithSourceFiles.
+func WithSourceDirectory(dirs ...string) Option {
+	return func(s *Service) {
+		for _, dir := range dirs {
+			s.Sources = append(s.Sources, SourceFile{Path: dir, Directory: true})
+		}
+	}
+}
+
+// WithSourceFilesFS sets source files to read from the given file system, such as an embed.FS.
+// It can be mixed with WithSourceFiles, e.g. to override defaults embedded in the binary with files on disk.
+// The files take precedence in the order they are added, and can be made optional with a question mark, the same as WithSourceFiles.
+func WithSourceFilesFS(fsys fs.FS, files ...string) Option {
+	return func(s *Service) {
+		for _, file := range files {
+			s.Sources = append(s.Sources, newSourceFile(file, fsys))
+		}
+	}
+}
+
+// WithFS sets the file system to read all the source files from, unless they are added with WithSourceFilesFS.
+// If this is not set, the files are read from the operating system.
+func WithFS(fsys fs.FS) Option {
+	return func(s *Service) {
+		s.FS = fsys
+	}
+}
+
 // WithSourceLoaders sets the source loaders to use to load the files.
 // It can be used multiple times to load from multiple loaders.
 // The first loader in the list takes the lowest precedence, and the last loader takes the highest precedence.
@@ -59,6 +129,38 @@ func WithJSONSchemaKeyModifier(modifier func(string) string) Option {
 	}
 }
 
+// WithJSONSchemaID sets the $id of the generated JSON schemas, which is the URI they are published at.
+// By default, it is left out.
+func WithJSONSchemaID(id string) Option {
+	return func(s *Service) {
+		s.JSONSchemaID = id
+	}
+}
+
+// WithJSONSchemaTitle sets the title of the generated JSON schemas.
+// By default, it is "Confuse Configuration Schema".
+func WithJSONSchemaTitle(title string) Option {
+	return func(s *Service) {
+		s.JSONSchemaTitle = title
+	}
+}
+
+// WithJSONSchemaDescription sets the description of the generated JSON schemas.
+// By default, it describes whether the schema is an exact or a fuzzy match of the config struct.
+func WithJSONSchemaDescription(description string) Option {
+	return func(s *Service) {
+		s.JSONSchemaDescription = description
+	}
+}
+
+// WithJSONSchemaDraft sets the JSON schema draft the generated JSON schemas are compatible with, e.g. JSONSchemaDraft07.
+// By default, it is JSONSchemaDraft2020_12.
+func WithJSONSchemaDraft(draft JSONSchemaDraft) Option {
+	return func(s *Service) {
+		s.JSONSchemaDraft = draft
+	}
+}
+
 // WithValidation sets the flag to indicate whether the unmarshalled configuration should be validated against the JSON schema.
 // If this is set to true, the unmarshalled configuration will be validated against the JSON schema.
 func WithValidation(shouldValidate bool) Option {
@@ -78,6 +180,7 @@ func WithCustomValidator(validator *validator.Validate) Option {
 
 // WithEnvironmentVariables sets the flag to indicate whether to use the environment variables to override the configuration.
 // If this is set to true, the environment variables will be used to override the configuration.
+// Fields can also be bound to specific environment variables with the env option of the config tag, e.g. `config:"host,env=DATABASE_HOST|PGHOST"`.
 func WithEnvironmentVariables(shouldUseEnvironmentVariables bool) Option {
 	return func(s *Service) {
 		s.ShouldUseEnvironmentVariables = shouldUseEnvironmentVariables
@@ -100,6 +203,35 @@ func WithEnvironmentVariablesSeparator(separator string) Option {
 	}
 }
 
+// WithEnvironmentVariablesListSeparator sets the separator between the items of a slice or the key value pairs of a map set by an environment variable.
+// E.g. with the default separator of ",", APP__HOSTS=a,b sets a slice and APP__PORTS=http=80,https=443 sets a map.
+// Values that are JSON arrays or objects, e.g. APP__HOSTS=["a","b"], are always decoded as JSON.
+func WithEnvironmentVariablesListSeparator(separator string) Option {
+	return func(s *Service) {
+		s.EnvironmentVariablesListSeparator = separator
+	}
+}
+
+// WithFlags sets the flag set and the arguments to parse with it, such as os.Args[1:], to override the configuration from the command line.
+// A flag is registered for every leaf field of the config struct, named after its path in kebab case, e.g. --database-config.host.
+// The help text of the flags comes from the usage option of the config tag and the validate tag, e.g. `config:"host,usage=The database host"`.
+// Only the flags that are explicitly set are used, and they take the highest precedence, above the environment variables.
+// The flag set is parsed when unmarshalling, unless it has already been parsed.
+func WithFlags(fs *flag.FlagSet, args []string) Option {
+	return func(s *Service) {
+		s.FlagSet = fs
+		s.FlagArgs = args
+	}
+}
+
+// WithDecodeHook adds hooks that convert the merged values into the types of the fields, such as custom types that can't implement encoding.TextUnmarshaler.
+// They run in order, before the built-in hooks.
+func WithDecodeHook(hooks ...mapstructure.DecodeHookFunc) Option {
+	return func(s *Service) {
+		s.DecodeHooks = append(s.DecodeHooks, hooks...)
+	}
+}
+
 // WithMergoConfig sets the list of options to use when merging the configuration using dario.cat/mergo.
 // By default it just uses mergo.WithOverride.
 func WithMergoConfig(config ...func(*mergo.Config)) Option {
@@ -107,3 +239,69 @@ func WithMergoConfig(config ...func(*mergo.Config)) Option {
 		s.MergoConfig = config
 	}
 }
+
+// WithProfiles sets the active profiles, such as "production" or "eu-west".
+// For every source file, the files named after it with each profile are layered after it in order, if they exist.
+// E.g. config.yaml with the profiles production and eu-west is followed by config.production.yaml and then config.eu-west.yaml.
+func WithProfiles(profiles ...string) Option {
+	return func(s *Service) {
+		s.Profiles = append(s.Profiles, profiles...)
+	}
+}
+
+// WithProfilesFromEnvironment sets the name of an environment variable, such as APP_PROFILE, containing a comma separated list of profiles.
+// They are active after the ones set with WithProfiles.
+func WithProfilesFromEnvironment(name string) Option {
+	return func(s *Service) {
+		s.ProfilesEnvironmentVariable = name
+	}
+}
+
+// WithFormat sets the decoder to use for source files with the given extension, such as ".ini".
+// It takes precedence over the formats registered with RegisterFormat and the built-in ones.
+func WithFormat(ext string, decoder Decoder) Option {
+	return func(s *Service) {
+		if s.Formats == nil {
+			s.Formats = make(map[string]Decoder)
+		}
+
+		s.Formats[normalizeExtension(ext)] = decoder
+	}
+}
+
+// WithStrict sets the flag to indicate whether keys in the sources that don't match any field should be rejected.
+// If this is set to true, unmarshalling fails with an UnknownKeysError listing every unknown key and the sources it came from.
+// Keys that only came from environment variables are ignored, as the environment is shared with everything else.
+func WithStrict(strict bool) Option {
+	return func(s *Service) {
+		s.ShouldRejectUnknownKeys = strict
+	}
+}
+
+// WithInterpolation enables resolving references in the values once every source is merged.
+// ${path.to.key} is replaced with the value of another key, ${env:NAME} with the value of an environment variable, and $${ is an escaped ${.
+func WithInterpolation() Option {
+	return func(s *Service) {
+		s.ShouldInterpolate = true
+	}
+}
+
+// WithSecretResolver sets the resolver of the secrets referenced by values with the given URL scheme, e.g. "file" for file:///run/secrets/db_password.
+// FileSecretResolver, EnvSecretResolver and ExecSecretResolver can be used for the file, env and exec schemes.
+func WithSecretResolver(scheme string, resolver SecretResolver) Option {
+	return func(s *Service) {
+		if s.SecretResolvers == nil {
+			s.SecretResolvers = make(map[string]SecretResolver)
+		}
+
+		s.SecretResolvers[strings.ToLower(strings.TrimSuffix(scheme, "://"))] = resolver
+	}
+}
+
+// WithWatchInterval sets how often Watch polls the source files for changes.
+// By default, it is set to one second.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(s *Service) {
+		s.WatchInterval = interval
+	}
+}
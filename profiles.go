@@ -0,0 +1,41 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profiles returns the active profiles, from Profiles followed by the comma separated list in ProfilesEnvironmentVariable.
+func (s *Service) profiles() []string {
+	profiles := append([]string(nil), s.Profiles...)
+
+	if s.ProfilesEnvironmentVariable != "" {
+		for _, profile := range strings.Split(os.Getenv(s.ProfilesEnvironmentVariable), ",") {
+			profile = strings.TrimSpace(profile)
+			if profile != "" {
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+
+	return profiles
+}
+
+// profileSourceFiles returns the optional files to layer on top of the source file for every active profile, in order.
+// E.g. config.yaml with the profiles production and eu-west is followed by config.production.yaml and config.eu-west.yaml.
+func (s *Service) profileSourceFiles(source SourceFile) []SourceFile {
+	profiles := s.profiles()
+	result := make([]SourceFile, 0, len(profiles))
+
+	ext := filepath.Ext(source.Path)
+	for _, profile := range profiles {
+		result = append(result, SourceFile{
+			Path:     strings.TrimSuffix(source.Path, ext) + "." + profile + ext,
+			FS:       source.FS,
+			Optional: true,
+		})
+	}
+
+	return result
+}
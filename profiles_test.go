@@ -0,0 +1,39 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Profiles(t *testing.T) {
+	type testStruct struct {
+		Host   string
+		Port   int
+		Region string
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("host: localhost\nport: 8080\nregion: none"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.production.yaml"), []byte("host: prod\nregion: default"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.eu-west.yaml"), []byte("region: eu-west"), 0644))
+
+	t.Run("should layer the profile files in order", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(base), WithProfiles("production", "eu-west", "missing")).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "prod", Port: 8080, Region: "eu-west"}, config)
+	})
+
+	t.Run("should read the profiles from the environment", func(t *testing.T) {
+		t.Setenv("CONFUSE_TEST_PROFILE", "eu-west, production")
+
+		var config testStruct
+		err := New(WithSourceFiles(base), WithProfilesFromEnvironment("CONFUSE_TEST_PROFILE")).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "prod", Port: 8080, Region: "default"}, config)
+	})
+}
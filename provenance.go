@@ -0,0 +1,295 @@
+package confuse
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OriginKind is the kind of source a configuration value came from.
+type OriginKind int
+
+const (
+	// OriginFile is a value that came from one of the SourceFiles or Sources.
+	OriginFile OriginKind = iota
+	// OriginLoader is a value that came from one of the SourceLoaders.
+	OriginLoader
+	// OriginEnvironment is a value that came from an environment variable.
+	OriginEnvironment
+	// OriginDefault is a value that came from the `default` option of the config tag.
+	OriginDefault
+	// OriginFlag is a value that came from a command-line flag.
+	OriginFlag
+)
+
+// Origin describes the source that set a configuration value.
+type Origin struct {
+	// Kind is the kind of source the value came from.
+	Kind OriginKind
+
+	// File is the path of the source file, if Kind is OriginFile.
+	File string
+
+	// Loader is the index of the loader in SourceLoaders, if Kind is OriginLoader.
+	Loader int
+
+	// EnvVar is the name of the environment variable, if Kind is OriginEnvironment.
+	EnvVar string
+
+	// Flag is the name of the command-line flag, if Kind is OriginFlag.
+	Flag string
+}
+
+func (o Origin) String() string {
+	switch o.Kind {
+	case OriginFile:
+		return "file " + o.File
+	case OriginLoader:
+		return fmt.Sprintf("loader %d", o.Loader)
+	case OriginEnvironment:
+		return "environment variable " + o.EnvVar
+	case OriginDefault:
+		return "default value"
+	case OriginFlag:
+		return "flag --" + o.Flag
+	default:
+		return "unknown origin"
+	}
+}
+
+// OverriddenValue is a value that was set by a source, but was overridden by a source with a higher precedence.
+type OverriddenValue struct {
+	Value  any
+	Origin Origin
+}
+
+// ValueProvenance describes where the final value of a configuration key came from.
+type ValueProvenance struct {
+	// Value is the final merged value of the key.
+	Value any
+
+	// Origin is the source that set the final value.
+	Origin Origin
+
+	// Overridden is the list of values that were set for the key by sources with a lower precedence, from lowest to highest.
+	Overridden []OverriddenValue
+}
+
+// Provenance maps the dot separated path of every configuration key to where its value came from.
+type Provenance map[string]ValueProvenance
+
+// Keys returns the key paths in the provenance report, sorted alphabetically.
+func (p Provenance) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Explain merges all the configured sources and decodes them into obj, like Unmarshal, and reports which source set every final configuration key.
+// The key paths are named after the fields of obj the same way as the JSON schema, falling back to the raw keys for keys that don't match a field.
+// Unlike Unmarshal, the configuration is not validated and no schema files are generated, so it can be used to debug invalid configuration.
+func (s *Service) Explain(obj any) (Provenance, error) {
+	fullMap, origins, err := s.merge(reflect.TypeOf(obj))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.decode(fullMap, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return origins.provenance(s, reflect.TypeOf(obj)), nil
+}
+
+// originTracker keeps track of the origin of every leaf value in the merged configuration, keyed by the raw dot separated path.
+type originTracker struct {
+	values map[string]*ValueProvenance
+	paths  map[string][]string
+}
+
+func newOriginTracker() *originTracker {
+	return &originTracker{
+		values: make(map[string]*ValueProvenance),
+		paths:  make(map[string][]string),
+	}
+}
+
+// track records the values of src that ended up in the merged map dst, after src was merged into it.
+// Values that were not applied by the merge, such as empty values that don't override, are not recorded.
+func (t *originTracker) track(dst map[string]any, src map[string]any, originFor func(path []string) Origin) {
+	walkLeaves(src, nil, func(path []string, value any) {
+		merged, ok := lookupPath(dst, path)
+		if !ok || !reflect.DeepEqual(merged, value) {
+			return
+		}
+
+		t.set(path, value, originFor(path))
+	})
+}
+
+func (t *originTracker) set(path []string, value any, origin Origin) {
+	key := strings.Join(path, ".")
+
+	// The value replaces anything that was previously nested under it, or that it was previously nested under
+	for existing := range t.values {
+		if strings.HasPrefix(existing, key+".") || strings.HasPrefix(key, existing+".") {
+			delete(t.values, existing)
+			delete(t.paths, existing)
+		}
+	}
+
+	var overridden []OverriddenValue
+	if previous, ok := t.values[key]; ok {
+		overridden = append(previous.Overridden, OverriddenValue{Value: previous.Value, Origin: previous.Origin})
+	}
+
+	t.values[key] = &ValueProvenance{
+		Value:      value,
+		Origin:     origin,
+		Overridden: overridden,
+	}
+	t.paths[key] = path
+}
+
+// provenance converts the tracked values into a Provenance report, with the paths named after the fields of the type t.
+// The keys of every source are named after the fields before merging, so every spelling of a key is tracked under the same path.
+// The values of secrets are redacted, like when rendering the configuration.
+func (t *originTracker) provenance(s *Service, objType reflect.Type) Provenance {
+	result := make(Provenance, len(t.values))
+	for key, value := range t.values {
+		path := t.paths[key]
+		report := ValueProvenance{
+			Value:  s.redactPath(value.Value, objType, path),
+			Origin: value.Origin,
+		}
+		for _, overridden := range value.Overridden {
+			report.Overridden = append(report.Overridden, OverriddenValue{Value: s.redactPath(overridden.Value, objType, path), Origin: overridden.Origin})
+		}
+
+		result[strings.Join(s.canonicalPath(objType, path), ".")] = report
+	}
+
+	return result
+}
+
+// redactPath returns the value at the path of raw map keys with the secrets it holds redacted, by following the type t.
+// Secrets are values of a Secret type or of fields with the secret option of the config tag.
+func (s *Service) redactPath(value any, t reflect.Type, path []string) any {
+	for _, key := range path {
+		if t == nil {
+			return value
+		}
+
+		t = dereference(t)
+		switch t.Kind() {
+		case reflect.Struct:
+			if isSecretType(t) {
+				return redacted
+			}
+
+			if isValueType(t) {
+				return value
+			}
+
+			field, ok := s.lookupField(t, key)
+			if !ok {
+				return value
+			}
+
+			if isSecretField(field) {
+				return redacted
+			}
+
+			t = field.Type
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return value
+		}
+	}
+
+	return s.redactValue(value, t)
+}
+
+// redactValue returns a copy of the value decoded into the type t with the secrets it holds redacted.
+func (s *Service) redactValue(value any, t reflect.Type) any {
+	if t == nil {
+		return value
+	}
+
+	t = dereference(t)
+	if isSecretType(t) {
+		return redacted
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for key, nested := range typed {
+			switch {
+			case t.Kind() == reflect.Map:
+				result[key] = s.redactValue(nested, t.Elem())
+			case t.Kind() == reflect.Struct && !isValueType(t):
+				field, ok := s.lookupField(t, key)
+				switch {
+				case !ok:
+					result[key] = nested
+				case isSecretField(field):
+					result[key] = redacted
+				default:
+					result[key] = s.redactValue(nested, field.Type)
+				}
+			default:
+				result[key] = nested
+			}
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(typed))
+		for i, item := range typed {
+			result[i] = s.redactValue(item, elemType(t))
+		}
+
+		return result
+	default:
+		return value
+	}
+}
+
+// walkLeaves calls fn for every value in the map that is not itself a map, with the path of keys leading to it.
+func walkLeaves(m map[string]any, path []string, fn func(path []string, value any)) {
+	for key, value := range m {
+		keyPath := append(append([]string{}, path...), key)
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			walkLeaves(nested, keyPath, fn)
+			continue
+		}
+
+		fn(keyPath, value)
+	}
+}
+
+// lookupPath returns the value at the path of keys in the nested map.
+func lookupPath(m map[string]any, path []string) (any, bool) {
+	var current any = m
+	for _, key := range path {
+		currentMap, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = currentMap[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
@@ -0,0 +1,111 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Explain(t *testing.T) {
+	type testStruct struct {
+		Name           string
+		DatabaseConfig struct {
+			Host string
+			Port int
+		}
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	override := filepath.Join(dir, "override.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("name: base\ndatabase_config:\n  host: localhost\n  port: 5432\n"), 0644))
+	require.NoError(t, os.WriteFile(override, []byte("DatabaseConfig:\n  port: 5433\n"), 0644))
+
+	t.Setenv("EXPLAIN_TEST__NAME", "env")
+
+	s := New(
+		WithSourceFiles(base, override),
+		WithSourceLoaders(func() (map[string]any, error) {
+			return map[string]any{"database_config": map[string]any{"host": "loader"}}, nil
+		}),
+		WithEnvironmentVariables(true),
+		WithEnvironmentVariablesPrefix("EXPLAIN_TEST__"),
+	)
+
+	var config testStruct
+	provenance, err := s.Explain(&config)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"database_config.host", "database_config.port", "name"}, provenance.Keys())
+
+	t.Run("should report the file that won", func(t *testing.T) {
+		port := provenance["database_config.port"]
+		require.Equal(t, 5433, port.Value)
+		require.Equal(t, Origin{Kind: OriginFile, File: override}, port.Origin)
+		require.Equal(t, []OverriddenValue{{Value: 5432, Origin: Origin{Kind: OriginFile, File: base}}}, port.Overridden)
+	})
+
+	t.Run("should report the loader that won", func(t *testing.T) {
+		host := provenance["database_config.host"]
+		require.Equal(t, "loader", host.Value)
+		require.Equal(t, Origin{Kind: OriginLoader, Loader: 0}, host.Origin)
+		require.Len(t, host.Overridden, 1)
+	})
+
+	t.Run("should report the environment variable that won", func(t *testing.T) {
+		name := provenance["name"]
+		require.Equal(t, "env", name.Value)
+		require.Equal(t, Origin{Kind: OriginEnvironment, EnvVar: "EXPLAIN_TEST__NAME"}, name.Origin)
+		require.Equal(t, "environment variable EXPLAIN_TEST__NAME", name.Origin.String())
+	})
+
+	t.Run("should decode into obj the values it reports", func(t *testing.T) {
+		require.Equal(t, "env", config.Name)
+		require.Equal(t, "loader", config.DatabaseConfig.Host)
+		require.Equal(t, 5433, config.DatabaseConfig.Port)
+
+		require.Equal(t, provenance["name"].Value, config.Name)
+		require.Equal(t, provenance["database_config.host"].Value, config.DatabaseConfig.Host)
+		require.Equal(t, provenance["database_config.port"].Value, config.DatabaseConfig.Port)
+	})
+}
+
+func TestService_ExplainSecrets(t *testing.T) {
+	type testStruct struct {
+		Password Secret[string]
+		Token    string `config:"token,secret=true"`
+		Backends []struct {
+			Key Secret[string]
+		}
+		Name string
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("password: file\ntoken: file\nbackends:\n  - key: file\nname: file\n"), 0644))
+
+	t.Setenv("EXPLAIN_SECRETS_TEST__PASSWORD", "env")
+	t.Setenv("EXPLAIN_SECRETS_TEST__TOKEN", "env")
+
+	var config testStruct
+	provenance, err := New(WithSourceFiles(path), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("EXPLAIN_SECRETS_TEST__")).Explain(&config)
+	require.NoError(t, err)
+
+	t.Run("should redact the values of secrets", func(t *testing.T) {
+		for _, key := range []string{"password", "token"} {
+			require.Equal(t, redacted, provenance[key].Value, key)
+			require.Equal(t, Origin{Kind: OriginEnvironment, EnvVar: "EXPLAIN_SECRETS_TEST__" + strings.ToUpper(key)}, provenance[key].Origin, key)
+			require.Equal(t, []OverriddenValue{{Value: redacted, Origin: Origin{Kind: OriginFile, File: path}}}, provenance[key].Overridden, key)
+		}
+
+		require.Equal(t, []any{map[string]any{"Key": redacted}}, provenance["backends"].Value)
+		require.Equal(t, "file", provenance["name"].Value)
+	})
+
+	t.Run("should still decode the secrets", func(t *testing.T) {
+		require.Equal(t, "env", config.Password.Value())
+		require.Equal(t, "env", config.Token)
+	})
+}
@@ -0,0 +1,313 @@
+package confuse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ls6-events/validjsonator"
+)
+
+const descriptionOption = "desc"
+
+// referenceTable is the reference of the keys of a single struct.
+type referenceTable struct {
+	path string
+	rows []referenceRow
+}
+
+// referenceRow is the reference of a single key.
+type referenceRow struct {
+	key         string
+	goType      string
+	schemaType  string
+	defaultVal  string
+	validation  string
+	envVar      string
+	description string
+}
+
+var referenceHeaders = []string{"Key", "Go type", "Schema type", "Default", "Validation", "Environment variable", "Description"}
+
+func (r referenceRow) cells() []string {
+	return []string{r.key, r.goType, r.schemaType, r.defaultVal, r.validation, r.envVar, r.description}
+}
+
+// GenerateReference returns the reference documentation of the config struct in "markdown" or "html" format.
+// It has a table for the root struct and every nested struct, listing for every key its Go type, its JSON schema type, its default value,
+// its validation rules, the environment variable that sets it, and its description from the desc option of the config tag, or the usage option, e.g. `config:"port,desc=The port to listen on"`.
+func (s *Service) GenerateReference(obj any, format string) ([]byte, error) {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("confuse: GenerateReference requires a struct")
+	}
+
+	tables, err := s.referenceTables(t, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "markdown", "md":
+		return writeMarkdownReference(tables), nil
+	case "html", "htm":
+		return writeHTMLReference(tables), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// referenceTables walks the struct type like schemaFromType, returning its table followed by the tables of the nested structs.
+// The path is the path of keys of the struct, and displayPath is the same path as shown in the documentation, with [] after lists.
+func (s *Service) referenceTables(t reflect.Type, path []string, displayPath []string) ([]referenceTable, error) {
+	table := referenceTable{path: strings.Join(displayPath, ".")}
+
+	var nested []referenceTable
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := s.fieldKey(field)
+		if key == "-" {
+			continue
+		}
+
+		_, options := extractValuesFromTag(field.Tag.Get(configTag))
+		fieldPath := append(append([]string{}, path...), key)
+		fieldDisplayPath := append(append([]string{}, displayPath...), key)
+
+		schema, err := s.schemaFromType(field.Type, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// The default of a secret is redacted, as the reference is usually published
+		defaultVal := options[defaultOption]
+		if _, ok := options[defaultOption]; ok && isSecretField(field) {
+			defaultVal = redacted
+		}
+
+		row := referenceRow{
+			key:         strings.Join(fieldDisplayPath, "."),
+			goType:      referenceGoType(field.Type),
+			schemaType:  referenceSchemaType(schema),
+			defaultVal:  defaultVal,
+			validation:  referenceValidation(field.Tag.Get("validate")),
+			description: fieldDescription(options),
+		}
+
+		fieldType := dereference(field.Type)
+		_, hasDefault := options[defaultOption]
+		switch {
+		case hasDefault || isValueType(fieldType):
+			row.envVar = s.referenceEnvVar(fieldPath, options)
+		case fieldType.Kind() == reflect.Struct:
+			tables, err := s.referenceTables(fieldType, fieldPath, fieldDisplayPath)
+			if err != nil {
+				return nil, err
+			}
+
+			nested = append(nested, tables...)
+		case (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && dereference(fieldType.Elem()).Kind() == reflect.Struct && !isValueType(dereference(fieldType.Elem())):
+			// The keys of the elements are set with indexed environment variables, such as APP__SERVERS__0__HOST
+			fieldDisplayPath[len(fieldDisplayPath)-1] += "[]"
+			tables, err := s.referenceTables(dereference(fieldType.Elem()), append(fieldPath, "0"), fieldDisplayPath)
+			if err != nil {
+				return nil, err
+			}
+
+			nested = append(nested, tables...)
+		default:
+			row.envVar = s.referenceEnvVar(fieldPath, options)
+		}
+
+		table.rows = append(table.rows, row)
+	}
+
+	return append([]referenceTable{table}, nested...), nil
+}
+
+// fieldDescription returns the description of the field from the desc option of the config tag, or the usage option of its flag.
+func fieldDescription(options map[string]string) string {
+	if description := options[descriptionOption]; description != "" {
+		return description
+	}
+
+	return options[usageOption]
+}
+
+// referenceEnvVar returns the names of the environment variables that set the key, which are the ones bound with the env option, or the one mapped from the path.
+func (s *Service) referenceEnvVar(path []string, options map[string]string) string {
+	if binding, ok := options[envOption]; ok {
+		return strings.Join(strings.Split(binding, "|"), ", ")
+	}
+
+	return s.envVarNameForPath(path)
+}
+
+// referenceSchemaType returns the type of the schema, with the type of its items and its format, e.g. "array of string" or "string (uri)".
+func referenceSchemaType(schema Schema) string {
+	result := schema.Type
+	if result == "" {
+		result = "any"
+	}
+
+	if schema.Items != nil {
+		result += " of " + referenceSchemaType(*schema.Items)
+	}
+
+	if schema.Format != "" {
+		result += " (" + schema.Format + ")"
+	}
+
+	return result
+}
+
+// referenceValidation returns the JSON schema keywords of the validate tag, e.g. "required, minimum: 1, maximum: 65535".
+func referenceValidation(tag string) string {
+	schema, required := validjsonator.ValidationTagsToSchema(tag)
+
+	var rules []string
+	if required {
+		rules = append(rules, "required")
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return strings.Join(rules, ", ")
+	}
+
+	var keywords map[string]any
+	_ = json.Unmarshal(data, &keywords)
+
+	return strings.Join(append(rules, referenceKeywords(keywords)...), ", ")
+}
+
+// referenceKeywords returns the keywords of the schema as "keyword: value", including the ones of the schemas in allOf, which validjsonator puts every rule in.
+func referenceKeywords(schema map[string]any) []string {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []string
+	for _, key := range keys {
+		if allOf, ok := schema[key].([]any); ok && key == "allOf" {
+			for _, nested := range allOf {
+				if nestedSchema, ok := nested.(map[string]any); ok {
+					result = append(result, referenceKeywords(nestedSchema)...)
+				}
+			}
+			continue
+		}
+
+		value, _ := json.Marshal(schema[key])
+		result = append(result, key+": "+strings.Trim(string(value), `"`))
+	}
+
+	return result
+}
+
+// referenceGoType returns the name of the Go type, with anonymous structs shown as struct rather than with all their fields.
+func referenceGoType(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + referenceGoType(t.Elem())
+	case reflect.Slice:
+		return "[]" + referenceGoType(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), referenceGoType(t.Elem()))
+	case reflect.Map:
+		return "map[" + referenceGoType(t.Key()) + "]" + referenceGoType(t.Elem())
+	case reflect.Struct:
+		return "struct"
+	}
+
+	return t.String()
+}
+
+func writeMarkdownReference(tables []referenceTable) []byte {
+	var b strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if table.path == "" {
+			b.WriteString("## Configuration\n\n")
+		} else {
+			b.WriteString("## `" + table.path + "`\n\n")
+		}
+
+		b.WriteString("| " + strings.Join(referenceHeaders, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(referenceHeaders)) + "\n")
+
+		for _, row := range table.rows {
+			cells := row.cells()
+			for j, cell := range cells {
+				cell = strings.ReplaceAll(cell, "|", `\|`)
+				cell = strings.ReplaceAll(cell, "\n", " ")
+
+				// The key, the Go type, the default value and the environment variable are code
+				if cell != "" && (j == 0 || j == 1 || j == 3 || j == 5) {
+					cell = "`" + cell + "`"
+				}
+
+				cells[j] = cell
+			}
+
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+func writeHTMLReference(tables []referenceTable) []byte {
+	var b strings.Builder
+	for _, table := range tables {
+		if table.path == "" {
+			b.WriteString("<h2>Configuration</h2>\n")
+		} else {
+			b.WriteString("<h2><code>" + html.EscapeString(table.path) + "</code></h2>\n")
+		}
+
+		b.WriteString("<table>\n<thead>\n<tr>")
+		for _, header := range referenceHeaders {
+			b.WriteString("<th>" + html.EscapeString(header) + "</th>")
+		}
+		b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+		for _, row := range table.rows {
+			b.WriteString("<tr>")
+			for j, cell := range row.cells() {
+				cell = html.EscapeString(cell)
+				if cell != "" && (j == 0 || j == 1 || j == 3 || j == 5) {
+					cell = "<code>" + cell + "</code>"
+				}
+
+				b.WriteString("<td>" + cell + "</td>")
+			}
+			b.WriteString("</tr>\n")
+		}
+
+		b.WriteString("</tbody>\n</table>\n")
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,66 @@
+package confuse
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GenerateReference(t *testing.T) {
+	type testStruct struct {
+		Port     int            `config:"port,default=8080,desc=The port to listen on" validate:"required"`
+		Timeout  time.Duration  `config:"timeout,default=30s"`
+		Address  net.IP         `config:"address"`
+		Password Secret[string] `config:"password,default=hunter2"`
+		Token    string         `config:"token,default=abc,secret=true"`
+		Hosts    []string       `config:"hosts,env=HOSTS|ADDRS"`
+		Database struct {
+			Host string `config:"host" validate:"hostname"`
+		} `config:"database,desc=The database | primary"`
+		Servers []struct {
+			Name string `config:"name" validate:"oneof=a b"`
+		}
+	}
+
+	service := New(WithEnvironmentVariablesPrefix("APP_"))
+
+	t.Run("should generate a markdown table per struct", func(t *testing.T) {
+		reference, err := service.GenerateReference(testStruct{}, "markdown")
+		require.NoError(t, err)
+		require.Equal(t, "## Configuration\n\n"+
+			"| Key | Go type | Schema type | Default | Validation | Environment variable | Description |\n"+
+			"| --- | --- | --- | --- | --- | --- | --- |\n"+
+			"| `port` | `int` | integer | `8080` | required | `APP_PORT` | The port to listen on |\n"+
+			"| `timeout` | `time.Duration` | string | `30s` |  | `APP_TIMEOUT` |  |\n"+
+			"| `address` | `net.IP` | string |  |  | `APP_ADDRESS` |  |\n"+
+			"| `password` | `confuse.Secret[string]` | string (password) | `******` |  | `APP_PASSWORD` |  |\n"+
+			"| `token` | `string` | string | `******` |  | `APP_TOKEN` |  |\n"+
+			"| `hosts` | `[]string` | array of string |  |  | `HOSTS, ADDRS` |  |\n"+
+			"| `database` | `struct` | object |  |  |  | The database \\| primary |\n"+
+			"| `servers` | `[]struct` | array of object |  |  |  |  |\n"+
+			"\n## `database`\n\n"+
+			"| Key | Go type | Schema type | Default | Validation | Environment variable | Description |\n"+
+			"| --- | --- | --- | --- | --- | --- | --- |\n"+
+			"| `database.host` | `string` | string |  | format: hostname | `APP_DATABASE__HOST` |  |\n"+
+			"\n## `servers[]`\n\n"+
+			"| Key | Go type | Schema type | Default | Validation | Environment variable | Description |\n"+
+			"| --- | --- | --- | --- | --- | --- | --- |\n"+
+			"| `servers[].name` | `string` | string |  | enum: [\"a\",\"b\"] | `APP_SERVERS__0__NAME` |  |\n",
+			string(reference))
+	})
+
+	t.Run("should generate escaped html tables", func(t *testing.T) {
+		reference, err := service.GenerateReference(&testStruct{}, "html")
+		require.NoError(t, err)
+		require.Contains(t, string(reference), "<h2><code>servers[]</code></h2>\n<table>\n")
+		require.Contains(t, string(reference), "<tr><td><code>servers[].name</code></td><td><code>string</code></td><td>string</td><td></td><td>enum: [&#34;a&#34;,&#34;b&#34;]</td><td><code>APP_SERVERS__0__NAME</code></td><td></td></tr>\n")
+	})
+
+	t.Run("should fail on unsupported formats", func(t *testing.T) {
+		_, err := service.GenerateReference(testStruct{}, "pdf")
+		require.True(t, errors.Is(err, ErrUnsupportedFormat))
+	})
+}
@@ -0,0 +1,165 @@
+package confuse
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const secretOption = "secret"
+
+// Render loads the configuration into obj like Unmarshal, and writes the result to w in the given format, such as "yaml", "json", "toml" or "env".
+// The keys are named the same way as in the JSON schema, and the values of Secret fields and fields with the secret option, e.g. `config:"password,secret=true"`, are replaced with ******.
+// obj can also be a pointer to a map, to render the merged sources without a config struct.
+func (s *Service) Render(obj any, format string, w io.Writer) error {
+	err := s.load(obj)
+	if err != nil {
+		return err
+	}
+
+	return s.render(obj, format, w)
+}
+
+// render writes the value of obj to w in the given format, without loading it.
+func (s *Service) render(obj any, format string, w io.Writer) error {
+	encoder, err := s.encoderFor(format)
+	if err != nil {
+		return err
+	}
+
+	values, ok := s.renderValue(reflect.ValueOf(obj)).(map[string]any)
+	if !ok {
+		return errors.New("confuse: Render requires a struct or a map")
+	}
+
+	data, err := encoder.Encode(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// encoderFor returns the encoder of the format, which is the decoder of the extension if it implements Encoder.
+func (s *Service) encoderFor(format string) (Encoder, error) {
+	decoder, ok := s.decoderFor(format)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	encoder, ok := decoder.(Encoder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s cannot be encoded", ErrUnsupportedFormat, format)
+	}
+
+	return encoder, nil
+}
+
+// renderValue converts the value into the maps, slices and scalars the encoders support.
+// Values that are decoded from strings, such as durations and URLs, are converted back into strings, and secrets are redacted.
+// It returns nil for nil values, which are left out.
+func (s *Service) renderValue(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+
+	if isSecretType(value.Type()) {
+		return redacted
+	}
+
+	switch typed := value.Interface().(type) {
+	case time.Duration:
+		return typed.String()
+	case url.URL:
+		return typed.String()
+	case encoding.TextMarshaler:
+		if value.Kind() == reflect.Ptr && value.IsNil() {
+			return nil
+		}
+
+		text, err := typed.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		return s.renderValue(value.Elem())
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+
+		return s.renderValue(value.Elem())
+	case reflect.Struct:
+		result := make(map[string]any)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			key := s.fieldKey(field)
+			if key == "-" {
+				continue
+			}
+
+			_, options := extractValuesFromTag(field.Tag.Get(configTag))
+			if secret, _ := strconv.ParseBool(options[secretOption]); secret {
+				result[key] = redacted
+				continue
+			}
+
+			fieldValue := s.renderValue(value.Field(i))
+			if fieldValue != nil {
+				result[key] = fieldValue
+			}
+		}
+
+		return result
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+
+		result := make(map[string]any, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			mapValue := s.renderValue(iter.Value())
+			if mapValue != nil {
+				result[fmt.Sprint(iter.Key().Interface())] = mapValue
+			}
+		}
+
+		return result
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return string(value.Bytes())
+		}
+
+		result := make([]any, value.Len())
+		for i := range result {
+			result[i] = s.renderValue(value.Index(i))
+		}
+
+		return result
+	default:
+		return value.Interface()
+	}
+}
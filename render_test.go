@@ -0,0 +1,143 @@
+package confuse
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Render(t *testing.T) {
+	type testStruct struct {
+		DatabaseConfig struct {
+			Host     string
+			Port     int
+			Password string `config:"password,secret=true"`
+		}
+		APIKey  Secret[string] `config:"api_key"`
+		Timeout time.Duration
+		Hosts   []string
+		Servers []struct {
+			Name string
+		}
+		Labels   map[string]string
+		Optional *struct {
+			Value string
+		}
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`database_config:
+  host: localhost
+  port: 5432
+  password: hunter2
+api_key: key
+timeout: 30s
+hosts: [a, b]
+servers:
+  - name: "first server"
+labels:
+  team: platform
+`), 0644))
+
+	render := func(t *testing.T, format string, opts ...Option) string {
+		var buffer bytes.Buffer
+		var result testStruct
+		err := New(append([]Option{WithSourceFiles(config)}, opts...)...).Render(&result, format, &buffer)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", result.DatabaseConfig.Password)
+
+		return buffer.String()
+	}
+
+	t.Run("should render yaml", func(t *testing.T) {
+		require.Equal(t, `api_key: '******'
+database_config:
+  host: localhost
+  password: '******'
+  port: 5432
+hosts:
+  - a
+  - b
+labels:
+  team: platform
+servers:
+  - name: first server
+timeout: 30s
+`, render(t, "yaml"))
+	})
+
+	t.Run("should render json", func(t *testing.T) {
+		require.JSONEq(t, `{
+			"api_key": "******",
+			"database_config": {"host": "localhost", "password": "******", "port": 5432},
+			"hosts": ["a", "b"],
+			"labels": {"team": "platform"},
+			"servers": [{"name": "first server"}],
+			"timeout": "30s"
+		}`, render(t, ".json"))
+	})
+
+	t.Run("should render toml", func(t *testing.T) {
+		output := render(t, "toml")
+		require.Contains(t, output, "timeout = '30s'")
+		require.Contains(t, output, "[database_config]\nhost = 'localhost'\npassword = '******'\nport = 5432\n")
+		require.Contains(t, output, "[[servers]]\nname = 'first server'\n")
+	})
+
+	t.Run("should render env with the names of the environment variables", func(t *testing.T) {
+		output := render(t, "env", WithEnvironmentVariablesPrefix("APP_"))
+		require.Equal(t, `APP_API_KEY="******"
+APP_DATABASE_CONFIG__HOST=localhost
+APP_DATABASE_CONFIG__PASSWORD="******"
+APP_DATABASE_CONFIG__PORT=5432
+APP_HOSTS=a,b
+APP_LABELS__TEAM=platform
+APP_SERVERS__0__NAME="first server"
+APP_TIMEOUT=30s
+`, output)
+
+		envFile := filepath.Join(dir, "rendered.env")
+		require.NoError(t, os.WriteFile(envFile, []byte(output), 0644))
+
+		var result testStruct
+		err := New(WithSourceFiles(envFile), WithEnvironmentVariablesPrefix("APP_")).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, "localhost", result.DatabaseConfig.Host)
+		require.Equal(t, []string{"a", "b"}, result.Hosts)
+		require.Equal(t, "first server", result.Servers[0].Name)
+		require.Equal(t, 30*time.Second, result.Timeout)
+	})
+
+	t.Run("should render a map of the merged sources", func(t *testing.T) {
+		var buffer bytes.Buffer
+		result := map[string]any{}
+		err := New(WithSourceFiles(config)).Render(&result, "json", &buffer)
+		require.NoError(t, err)
+		require.Contains(t, buffer.String(), `"password": "hunter2"`)
+	})
+
+	t.Run("should keep the types of the numbers of the merged sources", func(t *testing.T) {
+		tomlPath := filepath.Join(dir, "numbers.toml")
+		jsonPath := filepath.Join(dir, "numbers.json")
+		require.NoError(t, os.WriteFile(tomlPath, []byte("ratio = 1.0\n"), 0644))
+		require.NoError(t, os.WriteFile(jsonPath, []byte(`{"port": 8080, "scale": 2.0, "weight": 0.5}`), 0644))
+
+		var buffer bytes.Buffer
+		result := map[string]any{}
+		err := New(WithSourceFiles(tomlPath, jsonPath)).Render(&result, "toml", &buffer)
+		require.NoError(t, err)
+		require.Equal(t, "port = 8080\nratio = 1.0\nscale = 2.0\nweight = 0.5\n", buffer.String())
+	})
+
+	t.Run("should fail on formats that cannot be encoded", func(t *testing.T) {
+		var result testStruct
+		err := New(WithSourceFiles(config)).Render(&result, "ini", &bytes.Buffer{})
+		require.True(t, errors.Is(err, ErrUnsupportedFormat))
+	})
+}
@@ -0,0 +1,471 @@
+package confuse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ls6-events/validjsonator"
+	"gopkg.in/yaml.v3"
+)
+
+// sampleField is a key of a sample configuration, with the comments describing it.
+type sampleField struct {
+	key      string
+	path     []string
+	comments []string
+
+	// value is the value of a key that doesn't hold nested keys, which is nil if it has no sensible value.
+	value any
+
+	// nested is set for keys that hold the nested keys in fields, which are the keys of the first element if list is set.
+	nested bool
+	list   bool
+	fields []sampleField
+}
+
+// GenerateSample returns a sample configuration file for obj in the given format, such as "yaml", "toml", "json", "jsonc" or "env".
+// It has every key of the config struct, named the same way as in the JSON schema, with its default value or an empty value.
+// Each key is preceded by comments with its description from the desc or usage option of the config tag, whether it is required, its validate rules and its default value.
+// JSON doesn't support comments, so they are only written in the "jsonc" format.
+func (s *Service) GenerateSample(obj any, format string) ([]byte, error) {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("confuse: GenerateSample requires a struct")
+	}
+
+	fields, err := s.sampleFields(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	switch normalizeExtension(format) {
+	case ".yaml", ".yml":
+		writeYAMLSample(&b, fields, "")
+	case ".toml":
+		writeTOMLSample(&b, fields, nil)
+	case ".json":
+		writeJSONSample(&b, fields, "", false)
+		b.WriteString("\n")
+	case ".jsonc":
+		writeJSONSample(&b, fields, "", true)
+		b.WriteString("\n")
+	case ".env":
+		s.writeENVSample(&b, fields)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// sampleFields walks the struct type like schemaFromType, returning the sample of every field.
+func (s *Service) sampleFields(t reflect.Type, path []string) ([]sampleField, error) {
+	var result []sampleField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := s.fieldKey(field)
+		if key == "-" {
+			continue
+		}
+
+		_, options := extractValuesFromTag(field.Tag.Get(configTag))
+		sample := sampleField{
+			key:      key,
+			path:     append(append([]string{}, path...), key),
+			comments: sampleComments(field, options),
+		}
+
+		fieldType := dereference(field.Type)
+		_, hasDefault := options[defaultOption]
+
+		var err error
+		switch {
+		case hasDefault:
+			sample.value, err = s.sampleValue(field, options)
+		case fieldType.Kind() == reflect.Struct && !isValueType(fieldType):
+			sample.nested = true
+			sample.fields, err = s.sampleFields(fieldType, sample.path)
+		case (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && dereference(fieldType.Elem()).Kind() == reflect.Struct && !isValueType(dereference(fieldType.Elem())):
+			sample.nested = true
+			sample.list = true
+			sample.fields, err = s.sampleFields(dereference(fieldType.Elem()), append(sample.path, "0"))
+		default:
+			sample.value, err = s.sampleValue(field, options)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, sample)
+	}
+
+	return result, nil
+}
+
+// sampleValue returns the default value of the field, or an empty value of its type.
+// Secrets are always empty.
+func (s *Service) sampleValue(field reflect.StructField, options map[string]string) (any, error) {
+	t := dereference(field.Type)
+	secret, _ := strconv.ParseBool(options[secretOption])
+	if isSecretType(t) {
+		secret = true
+		t = dereference(secretValueType(t))
+	}
+
+	if !secret {
+		value, ok, err := s.defaultValue(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return s.renderValue(reflect.ValueOf(value)), nil
+		}
+	}
+
+	if isValueType(t) {
+		return "", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil, nil
+	case reflect.Map:
+		return map[string]any{}, nil
+	case reflect.Slice:
+		return []any{}, nil
+	default:
+		return s.renderValue(reflect.Zero(t)), nil
+	}
+}
+
+// sampleComments returns the comments describing the field, from its description, the validate tag and the default option.
+func sampleComments(field reflect.StructField, options map[string]string) []string {
+	var comments []string
+	if description := fieldDescription(options); description != "" {
+		comments = append(comments, description)
+	}
+
+	validation := field.Tag.Get("validate")
+	if _, required := validjsonator.ValidationTagsToSchema(validation); required {
+		comments = append(comments, "Required.")
+	}
+
+	var rules []string
+	for _, rule := range strings.Split(validation, ",") {
+		if rule != "" && rule != "required" {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) > 0 {
+		comments = append(comments, "Validation: "+strings.Join(rules, ","))
+	}
+
+	secret, _ := strconv.ParseBool(options[secretOption])
+	if value, ok := options[defaultOption]; ok && !secret && !isSecretType(dereference(field.Type)) {
+		comments = append(comments, "Default: "+value)
+	}
+
+	return comments
+}
+
+func dereference(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// writeSampleComments writes the comments of a field.
+// Fields with comments are separated from the other fields by a blank line, unless they are the first in their block.
+func writeSampleComments(b *strings.Builder, first bool, previous []string, indent string, prefix string, comments []string) {
+	if !first && (len(previous) > 0 || len(comments) > 0) {
+		b.WriteString("\n")
+	}
+
+	for _, comment := range comments {
+		b.WriteString(indent + prefix + comment + "\n")
+	}
+}
+
+func writeYAMLSample(b *strings.Builder, fields []sampleField, indent string) {
+	var previous []string
+	for i, field := range fields {
+		writeSampleComments(b, i == 0, previous, indent, "# ", field.comments)
+		previous = field.comments
+
+		key := yamlSampleValue(field.key)
+		switch {
+		case field.nested && len(field.fields) == 0 && field.list:
+			b.WriteString(indent + key + ": []\n")
+		case field.nested && len(field.fields) == 0:
+			b.WriteString(indent + key + ": {}\n")
+		case field.list:
+			// The keys of the element are indented past the dash, which replaces the indentation of the first line
+			var element strings.Builder
+			writeYAMLSample(&element, field.fields, indent+"    ")
+
+			b.WriteString(indent + key + ":\n")
+			b.WriteString(indent + "  - " + strings.TrimPrefix(element.String(), indent+"    "))
+		case field.nested:
+			b.WriteString(indent + key + ":\n")
+			writeYAMLSample(b, field.fields, indent+"  ")
+		case field.value == nil:
+			b.WriteString(indent + key + ":\n")
+		default:
+			b.WriteString(indent + key + ": " + yamlSampleValue(field.value) + "\n")
+		}
+	}
+}
+
+// yamlSampleValue returns the value as YAML on a single line, using the flow style for lists and maps.
+func yamlSampleValue(value any) string {
+	switch value.(type) {
+	case []any, map[string]any:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+
+	data, _ := yaml.Marshal(value)
+
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+var tomlBareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func writeTOMLSample(b *strings.Builder, fields []sampleField, table []string) {
+	// The keys of a table must come before its nested tables
+	first := true
+	var previous []string
+	for _, field := range fields {
+		if field.nested {
+			continue
+		}
+
+		writeSampleComments(b, first, previous, "", "# ", field.comments)
+		first = false
+		previous = field.comments
+
+		if field.value == nil {
+			b.WriteString("# " + tomlSampleKey(field.key) + " =\n")
+			continue
+		}
+
+		b.WriteString(tomlSampleKey(field.key) + " = " + tomlSampleValue(field.value) + "\n")
+	}
+
+	for _, field := range fields {
+		if !field.nested {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		for _, comment := range field.comments {
+			b.WriteString("# " + comment + "\n")
+		}
+
+		path := append(append([]string{}, table...), tomlSampleKey(field.key))
+		if field.list {
+			b.WriteString("[[" + strings.Join(path, ".") + "]]\n")
+		} else {
+			b.WriteString("[" + strings.Join(path, ".") + "]\n")
+		}
+
+		writeTOMLSample(b, field.fields, path)
+	}
+}
+
+func tomlSampleKey(key string) string {
+	if tomlBareKeyRegexp.MatchString(key) {
+		return key
+	}
+
+	data, _ := json.Marshal(key)
+
+	return string(data)
+}
+
+// tomlSampleValue returns the value as TOML, using inline tables for maps.
+// JSON strings, numbers and arrays are valid TOML, so they are used for the rest.
+func tomlSampleValue(value any) string {
+	switch typed := value.(type) {
+	case []any:
+		items := make([]string, len(typed))
+		for i, item := range typed {
+			items[i] = tomlSampleValue(item)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		if len(typed) == 0 {
+			return "{}"
+		}
+
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = tomlSampleKey(key) + " = " + tomlSampleValue(typed[key])
+		}
+
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	}
+
+	data, _ := json.Marshal(value)
+
+	return string(data)
+}
+
+func writeJSONSample(b *strings.Builder, fields []sampleField, indent string, comments bool) {
+	if len(fields) == 0 {
+		b.WriteString("{}")
+		return
+	}
+
+	b.WriteString("{\n")
+	var previous []string
+	for i, field := range fields {
+		if comments {
+			writeSampleComments(b, i == 0, previous, indent+"  ", "// ", field.comments)
+			previous = field.comments
+		}
+
+		key, _ := json.Marshal(field.key)
+		b.WriteString(indent + "  " + string(key) + ": ")
+
+		switch {
+		case field.list:
+			b.WriteString("[\n" + indent + "    ")
+			writeJSONSample(b, field.fields, indent+"    ", comments)
+			b.WriteString("\n" + indent + "  ]")
+		case field.nested:
+			writeJSONSample(b, field.fields, indent+"  ", comments)
+		default:
+			value, _ := json.Marshal(field.value)
+			b.WriteString(string(value))
+		}
+
+		if i < len(fields)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+func (s *Service) writeENVSample(b *strings.Builder, fields []sampleField) {
+	var previous []string
+	for i, line := range s.envSampleLines(fields, nil) {
+		writeSampleComments(b, i == 0, previous, "", "# ", line.comments)
+		previous = line.comments
+
+		b.WriteString(line.text + "\n")
+	}
+}
+
+// envSampleLine is a single environment variable of a sample .env file.
+type envSampleLine struct {
+	comments []string
+	text     string
+}
+
+// envSampleLines flattens the fields into environment variables.
+// The comments of nested keys are written before the first of their environment variables, as the comments in inherited.
+func (s *Service) envSampleLines(fields []sampleField, inherited []string) []envSampleLine {
+	var result []envSampleLine
+	for i, field := range fields {
+		comments := field.comments
+		if i == 0 && len(inherited) > 0 {
+			comments = append(append([]string{}, inherited...), comments...)
+		}
+
+		if field.nested {
+			result = append(result, s.envSampleLines(field.fields, comments)...)
+			continue
+		}
+
+		text := s.envVarNameForPath(field.path) + "=" + s.envSampleValue(field.value)
+
+		// An empty value can't be decoded into a list or a map, so they are left unset
+		switch typed := field.value.(type) {
+		case nil:
+			text = "# " + text
+		case []any:
+			if len(typed) == 0 {
+				text = "# " + text
+			}
+		case map[string]any:
+			if len(typed) == 0 {
+				text = "# " + text
+			}
+		}
+
+		result = append(result, envSampleLine{comments: comments, text: text})
+	}
+
+	return result
+}
+
+// envSampleValue returns the value as it would be set in an environment variable, with lists and maps joined by EnvironmentVariablesListSeparator.
+func (s *Service) envSampleValue(value any) string {
+	separator := s.EnvironmentVariablesListSeparator
+	if separator == "" {
+		separator = ","
+	}
+
+	var result string
+	switch typed := value.(type) {
+	case nil:
+	case []any:
+		list, ok := s.joinENVList(typed)
+		if !ok && len(typed) > 0 {
+			data, _ := json.Marshal(typed)
+			list = string(data)
+		}
+
+		result = list
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + fmt.Sprint(typed[key])
+		}
+
+		result = strings.Join(pairs, separator)
+	default:
+		result = fmt.Sprint(typed)
+	}
+
+	if result == "" {
+		return ""
+	}
+
+	return quoteDotenv(result)
+}
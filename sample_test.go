@@ -0,0 +1,119 @@
+package confuse
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GenerateSample(t *testing.T) {
+	type testStruct struct {
+		Name     string         `config:"name,usage=The name of the service" validate:"required"`
+		Port     int            `config:"port,default=8080" validate:"min=1,max=65535"`
+		Timeout  time.Duration  `config:"timeout,default=30s"`
+		Password Secret[string] `config:"password"`
+		Hosts    []string       `config:"hosts,default=localhost"`
+		Database struct {
+			Host    string `config:"host" validate:"required,hostname"`
+			Options map[string]int
+		} `config:"database,usage=The database to connect to"`
+		Servers []struct {
+			Name string
+		}
+	}
+
+	service := New(WithEnvironmentVariablesPrefix("APP_"))
+
+	t.Run("should generate a commented yaml file", func(t *testing.T) {
+		sample, err := service.GenerateSample(testStruct{}, "yaml")
+		require.NoError(t, err)
+		require.Equal(t, `# The name of the service
+# Required.
+name: ""
+
+# Validation: min=1,max=65535
+# Default: 8080
+port: 8080
+
+# Default: 30s
+timeout: 30s
+
+password: ""
+
+# Default: localhost
+hosts: ["localhost"]
+
+# The database to connect to
+database:
+  # Required.
+  # Validation: hostname
+  host: ""
+
+  options: {}
+
+servers:
+  - name: ""
+`, string(sample))
+	})
+
+	t.Run("should generate a commented env file", func(t *testing.T) {
+		sample, err := service.GenerateSample(&testStruct{}, "env")
+		require.NoError(t, err)
+		require.Contains(t, string(sample), "# The database to connect to\n# Required.\n# Validation: hostname\nAPP_DATABASE__HOST=\n")
+		require.Contains(t, string(sample), "APP_HOSTS=localhost\n")
+		require.Contains(t, string(sample), "APP_SERVERS__0__NAME=\n")
+	})
+
+	t.Run("should generate a commented jsonc file", func(t *testing.T) {
+		sample, err := service.GenerateSample(testStruct{}, "jsonc")
+		require.NoError(t, err)
+		require.Contains(t, string(sample), "  // The name of the service\n  // Required.\n  \"name\": \"\",\n")
+
+		var result map[string]any
+		withoutComments := regexp.MustCompile(`(?m)^\s*//.*$`).ReplaceAll(sample, nil)
+		require.NoError(t, json.Unmarshal(withoutComments, &result))
+		require.Equal(t, float64(8080), result["port"])
+	})
+
+	t.Run("should generate a json file without comments", func(t *testing.T) {
+		sample, err := service.GenerateSample(testStruct{}, "json")
+		require.NoError(t, err)
+		require.NotContains(t, string(sample), "//")
+
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(sample, &result))
+		require.Equal(t, float64(8080), result["port"])
+	})
+
+	t.Run("should generate files that can be loaded", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, format := range []string{"yaml", "toml", "json", "env"} {
+			sample, err := service.GenerateSample(testStruct{}, format)
+			require.NoError(t, err)
+
+			path := filepath.Join(dir, "config."+format)
+			require.NoError(t, os.WriteFile(path, sample, 0644))
+
+			var result testStruct
+			err = New(WithSourceFiles(path), WithEnvironmentVariablesPrefix("APP_")).Unmarshal(&result)
+			require.NoError(t, err, format)
+			require.Equal(t, 8080, result.Port, format)
+			require.Equal(t, 30*time.Second, result.Timeout, format)
+			require.Equal(t, []string{"localhost"}, result.Hosts, format)
+		}
+	})
+
+	t.Run("should fail on unsupported formats", func(t *testing.T) {
+		_, err := service.GenerateSample(testStruct{}, "ini")
+		require.True(t, errors.Is(err, ErrUnsupportedFormat))
+
+		_, err = service.GenerateSample("not a struct", "yaml")
+		require.Error(t, err)
+	})
+}
@@ -2,34 +2,53 @@ package confuse
 
 import (
 	"errors"
+	"net"
 	"reflect"
+	"regexp"
+	"strconv"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/ls6-events/validjsonator"
 )
 
-func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (validjsonator.Schema, error) {
-	var schema validjsonator.Schema
+func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (Schema, error) {
+	var schema Schema
+
+	// Secrets are described by the schema of the value they hold, which is never read back
+	if isSecretType(t) {
+		schema, err := s.schemaFromType(secretValueType(t), fuzzy)
+		if err != nil {
+			return Schema{}, err
+		}
+
+		schema.WriteOnly = true
+		if schema.Type == "string" {
+			schema.Format = "password"
+		}
+
+		return schema, nil
+	}
+
+	if schema, ok := valueTypeSchema(t); ok {
+		return schema, nil
+	}
 
 	switch t.Kind() {
 	case reflect.Struct:
 		schema.Type = "object"
-		schema.Properties = make(map[string]validjsonator.Schema)
+		schema.Properties = make(map[string]Schema)
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			configTagName, _ := extractValuesFromTag(field.Tag.Get(configTag))
+			configTagName := s.fieldKey(field)
 
 			if configTagName == "-" {
 				continue
 			}
 
-			if configTagName == "" {
-				configTagName = s.JSONSchemaKeyModifier(field.Name)
-			}
-
 			fieldSchema, err := s.schemaFromType(field.Type, fuzzy)
 			if err != nil {
-				return validjsonator.Schema{}, err
+				return Schema{}, err
 			}
 
 			if s.ShouldValidate {
@@ -38,25 +57,37 @@ func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (validjsonator.Sche
 					schema.Required = append(schema.Required, configTagName)
 				}
 
-				err = mergo.Merge(&fieldSchema, validationSchema, mergo.WithOverride)
+				err = mergo.Merge(&fieldSchema.Schema, validationSchema, mergo.WithOverride)
 				if err != nil {
-					return validjsonator.Schema{}, err
+					return Schema{}, err
 				}
 			}
 
+			defaultValue, ok, err := s.defaultValue(field)
+			if err != nil {
+				return Schema{}, err
+			}
+
+			// The default of a secret is left out, as the schema is usually published
+			_, options := extractValuesFromTag(field.Tag.Get(configTag))
+			secret, _ := strconv.ParseBool(options[secretOption])
+			if ok && !secret && !isSecretType(dereference(field.Type)) {
+				fieldSchema.Default = s.renderValue(reflect.ValueOf(defaultValue))
+			}
+
 			schema.Properties[configTagName] = fieldSchema
 		}
 	case reflect.Ptr:
 		var err error
 		schema, err = s.schemaFromType(t.Elem(), fuzzy)
 		if err != nil {
-			return validjsonator.Schema{}, err
+			return Schema{}, err
 		}
 	case reflect.Slice:
 		schema.Type = "array"
 		itemsSchema, err := s.schemaFromType(t.Elem(), fuzzy)
 		if err != nil {
-			return validjsonator.Schema{}, err
+			return Schema{}, err
 		}
 
 		schema.Items = &itemsSchema
@@ -64,7 +95,7 @@ func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (validjsonator.Sche
 		schema.Type = "array"
 		itemsSchema, err := s.schemaFromType(t.Elem(), fuzzy)
 		if err != nil {
-			return validjsonator.Schema{}, err
+			return Schema{}, err
 		}
 
 		schema.Items = &itemsSchema
@@ -74,7 +105,7 @@ func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (validjsonator.Sche
 		schema.Type = "object"
 		additionalPropertiesSchema, err := s.schemaFromType(t.Elem(), fuzzy)
 		if err != nil {
-			return validjsonator.Schema{}, err
+			return Schema{}, err
 		}
 
 		schema.AdditionalProperties = &additionalPropertiesSchema
@@ -91,8 +122,53 @@ func (s *Service) schemaFromType(t reflect.Type, fuzzy bool) (validjsonator.Sche
 	case reflect.Interface:
 		// Any type, we just leave the schema empty
 	default:
-		return validjsonator.Schema{}, errors.New("unknown type")
+		return Schema{}, errors.New("unknown type")
 	}
 
 	return schema, nil
 }
+
+// durationPattern matches the strings time.ParseDuration accepts, e.g. 1h30m or 250ms.
+const durationPattern = `^(0|[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+)$`
+
+// valueTypeSchema returns the schema of the types that are decoded from strings by the built-in decode hooks, rather than from their kind.
+func valueTypeSchema(t reflect.Type) (Schema, bool) {
+	var schema Schema
+	schema.Type = "string"
+
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		schema.Pattern = durationPattern
+	case reflect.TypeOf(time.Time{}):
+		schema.Format = "date-time"
+	case reflect.TypeOf(net.IP{}):
+		schema.AnyOf = []validjsonator.Schema{{Format: "ipv4"}, {Format: "ipv6"}}
+	case urlType:
+		schema.Format = "uri"
+	case reflect.TypeOf(regexp.Regexp{}):
+		schema.Format = "regex"
+	case reflect.TypeOf(ByteSize(0)):
+		schema.Pattern = ByteSizePattern
+	default:
+		// Other types that implement encoding.TextUnmarshaler are strings too
+		// Those with a scalar kind, such as slog.Level, can also be decoded from their kind
+		if !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			return Schema{}, false
+		}
+
+		switch t.Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			return schema, true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return Schema{Schema: validjsonator.Schema{AnyOf: []validjsonator.Schema{{Type: "string"}, {Type: "integer"}}}}, true
+		case reflect.Float32, reflect.Float64:
+			return Schema{Schema: validjsonator.Schema{AnyOf: []validjsonator.Schema{{Type: "string"}, {Type: "number"}}}}, true
+		case reflect.Bool:
+			return Schema{Schema: validjsonator.Schema{AnyOf: []validjsonator.Schema{{Type: "string"}, {Type: "boolean"}}}}, true
+		default:
+			return Schema{}, false
+		}
+	}
+
+	return schema, true
+}
@@ -4,8 +4,30 @@ import "github.com/ls6-events/validjsonator"
 
 type JSONSchema struct {
 	SchemaUri   string `json:"$schema"`
-	IDUri       string `json:"$id"`
+	IDUri       string `json:"$id,omitempty"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	validjsonator.Schema
+	Schema
+}
+
+// JSONSchemaDraft is the URI of the JSON schema draft, used as the $schema of the generated JSON schemas.
+type JSONSchemaDraft string
+
+// The JSON schema drafts the generated JSON schemas are compatible with.
+const (
+	JSONSchemaDraft2020_12 JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	JSONSchemaDraft2019_09 JSONSchemaDraft = "https://json-schema.org/draft/2019-09/schema"
+	JSONSchemaDraft07      JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+)
+
+// Schema is a JSON schema for a single property.
+// It extends validjsonator.Schema with the keywords that are generated from the config struct rather than the validation tags.
+type Schema struct {
+	validjsonator.Schema `yaml:",inline"`
+
+	Default              any               `json:"default,omitempty" yaml:"default,omitempty"`
+	Items                *Schema           `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *Schema           `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	WriteOnly            bool              `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
 }
@@ -0,0 +1,117 @@
+package confuse
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// redacted is what a Secret prints instead of its value.
+const redacted = "******"
+
+// Secret holds a configuration value that must not leak into logs or dumps, such as a password.
+// It decodes like a field of type T, but prints ****** with fmt, encoding/json, encoding and log/slog.
+// The value itself is only available through Value.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding the value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the value of the secret.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String implements fmt.Stringer, without revealing the value.
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer, without revealing the value.
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter, so every verb prints ****** rather than the value.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// MarshalJSON implements json.Marshaler, without revealing the value.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText implements encoding.TextMarshaler, without revealing the value.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalYAML implements yaml.Marshaler, without revealing the value.
+func (s Secret[T]) MarshalYAML() (any, error) {
+	return redacted, nil
+}
+
+// LogValue implements slog.LogValuer, without revealing the value.
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// secretValue returns a pointer to the value, so it can be decoded into.
+func (s *Secret[T]) secretValue() any {
+	return &s.value
+}
+
+// secret is implemented by pointers to every Secret type.
+type secret interface {
+	secretValue() any
+}
+
+var secretType = reflect.TypeOf((*secret)(nil)).Elem()
+
+// isSecretType reports whether the type is a Secret.
+func isSecretType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(secretType)
+}
+
+// isSecretField reports whether the field holds a secret, either with a Secret type or with the secret option of the config tag.
+func isSecretField(field reflect.StructField) bool {
+	_, options := extractValuesFromTag(field.Tag.Get(configTag))
+	secret, _ := strconv.ParseBool(options[secretOption])
+
+	return secret || isSecretType(dereference(field.Type))
+}
+
+// secretHook decodes values into Secret fields, by decoding them into the type of the value it holds.
+func (s *Service) secretHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from == to || !isSecretType(to) {
+			return data, nil
+		}
+
+		result := reflect.New(to)
+		decoder, err := mapstructure.NewDecoder(s.decoderConfig(result.Interface().(secret).secretValue(), nil))
+		if err != nil {
+			return nil, err
+		}
+
+		err = decoder.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Elem().Interface(), nil
+	}
+}
+
+// secretValueType returns the type of the value held by the Secret type.
+func secretValueType(t reflect.Type) reflect.Type {
+	return reflect.TypeOf(reflect.New(t).Interface().(secret).secretValue()).Elem()
+}
@@ -0,0 +1,87 @@
+package confuse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	type testStruct struct {
+		Password Secret[string]
+		Pin      Secret[int] `config:"pin,default=1234"`
+		Keys     Secret[[]string]
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("password: hunter2\nkeys: [a, b]\n"), 0644))
+
+	var result testStruct
+	err := New(WithSourceFiles(config)).Unmarshal(&result)
+	require.NoError(t, err)
+
+	t.Run("should decode the value", func(t *testing.T) {
+		require.Equal(t, "hunter2", result.Password.Value())
+		require.Equal(t, 1234, result.Pin.Value())
+		require.Equal(t, []string{"a", "b"}, result.Keys.Value())
+	})
+
+	t.Run("should decode values from the environment", func(t *testing.T) {
+		t.Setenv("SECRET_TEST_PASSWORD", "from-env")
+
+		var result testStruct
+		err := New(WithSourceFiles(config), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("SECRET_TEST_")).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, "from-env", result.Password.Value())
+	})
+
+	t.Run("should not print the value", func(t *testing.T) {
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%d", "%q"} {
+			require.NotContains(t, fmt.Sprintf(format, result), "hunter2", format)
+			require.NotContains(t, fmt.Sprintf(format, result), "1234", format)
+		}
+		require.Equal(t, "******", result.Password.String())
+
+		data, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"Password":"******","Pin":"******","Keys":"******"}`, string(data))
+
+		var buffer bytes.Buffer
+		slog.New(slog.NewTextHandler(&buffer, nil)).Info("config", "password", result.Password)
+		require.Contains(t, buffer.String(), "password=******")
+	})
+
+	t.Run("should mark the schema as a write only password", func(t *testing.T) {
+		schema, err := New().schemaFromType(reflect.TypeOf(result), false)
+		require.NoError(t, err)
+
+		password := schema.Properties["password"]
+		require.Equal(t, "string", password.Type)
+		require.Equal(t, "password", password.Format)
+		require.True(t, password.WriteOnly)
+
+		pin := schema.Properties["pin"]
+		require.Equal(t, "integer", pin.Type)
+		require.Empty(t, pin.Format)
+		require.True(t, pin.WriteOnly)
+		require.Nil(t, pin.Default)
+	})
+
+	t.Run("should leave the defaults of secret fields out of the schema", func(t *testing.T) {
+		type secretOptionStruct struct {
+			Token string `config:"token,secret=true,default=abc"`
+		}
+
+		schema, err := New().schemaFromType(reflect.TypeOf(secretOptionStruct{}), false)
+		require.NoError(t, err)
+		require.Nil(t, schema.Properties["token"].Default)
+	})
+}
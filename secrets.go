@@ -0,0 +1,157 @@
+package confuse
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SecretResolver resolves references to secrets, like file:///run/secrets/db_password, into their values.
+type SecretResolver interface {
+	// Resolve returns the value of the secret, given the reference without its scheme, e.g. /run/secrets/db_password.
+	Resolve(reference string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to allow the use of ordinary functions as a SecretResolver.
+type SecretResolverFunc func(reference string) (string, error)
+
+// Resolve calls f(reference).
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// FileSecretResolver returns a SecretResolver that reads the secret from a file, e.g. file:///run/secrets/db_password.
+// A single trailing newline is removed, as most tools add one when writing the file.
+func FileSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(reference string) (string, error) {
+		data, err := os.ReadFile(reference)
+		if err != nil {
+			return "", err
+		}
+
+		return trimTrailingNewline(string(data)), nil
+	})
+}
+
+// EnvSecretResolver returns a SecretResolver that reads the secret from an environment variable, e.g. env://PGPASSWORD.
+// It fails if the environment variable is not set.
+func EnvSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(reference string) (string, error) {
+		value, ok := os.LookupEnv(reference)
+		if !ok {
+			return "", errors.New("environment variable " + reference + " is not set")
+		}
+
+		return value, nil
+	})
+}
+
+// ExecSecretResolver returns a SecretResolver that runs a command and uses its output as the secret, e.g. exec://pass show db.
+// The command is split on whitespace and run without a shell. A single trailing newline is removed from the output.
+func ExecSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(reference string) (string, error) {
+		args := strings.Fields(reference)
+		if len(args) == 0 {
+			return "", errors.New("empty command")
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return "", errors.New(err.Error() + ": " + message)
+			}
+
+			return "", err
+		}
+
+		return trimTrailingNewline(string(output)), nil
+	})
+}
+
+func trimTrailingNewline(value string) string {
+	value = strings.TrimSuffix(value, "\n")
+	return strings.TrimSuffix(value, "\r")
+}
+
+// SecretError is returned when the secret referenced by the value of a key cannot be resolved.
+type SecretError struct {
+	// Key is the dot separated path of the key whose value is the reference.
+	Key string
+
+	// Reference is the reference to the secret, including its scheme.
+	Reference string
+
+	// Err is the error returned by the SecretResolver.
+	Err error
+}
+
+func (e *SecretError) Error() string {
+	return "resolving secret " + e.Reference + " for " + e.Key + ": " + e.Err.Error()
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+// resolveSecrets replaces the string values of the merged configuration that reference a secret with a registered scheme, in place.
+// The keys in errors are named after the fields of objType.
+func (s *Service) resolveSecrets(fullMap map[string]any, objType reflect.Type) error {
+	_, err := s.resolveSecretsIn(fullMap, nil, objType)
+	return err
+}
+
+func (s *Service) resolveSecretsIn(value any, path []string, objType reflect.Type) (any, error) {
+	switch typed := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			resolved, err := s.resolveSecretsIn(typed[key], append(append([]string{}, path...), key), objType)
+			if err != nil {
+				return nil, err
+			}
+
+			typed[key] = resolved
+		}
+	case []any:
+		for index, item := range typed {
+			resolved, err := s.resolveSecretsIn(item, append(append([]string{}, path...), strconv.Itoa(index)), objType)
+			if err != nil {
+				return nil, err
+			}
+
+			typed[index] = resolved
+		}
+	case string:
+		scheme, reference, ok := strings.Cut(typed, "://")
+		if !ok {
+			return typed, nil
+		}
+
+		resolver, ok := s.SecretResolvers[strings.ToLower(scheme)]
+		if !ok {
+			return typed, nil
+		}
+
+		secret, err := resolver.Resolve(reference)
+		if err != nil {
+			return nil, &SecretError{Key: strings.Join(s.canonicalPath(objType, path), "."), Reference: typed, Err: err}
+		}
+
+		return secret, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,73 @@
+package confuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SecretResolvers(t *testing.T) {
+	type testStruct struct {
+		Database struct {
+			Password string
+		}
+		APIKey  string
+		Token   string
+		Plain   string
+		Servers []string
+	}
+
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "db_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("hunter2\n"), 0600))
+
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`database:
+  password: "file://`+secretFile+`"
+api_key: "env://SECRETS_TEST_API_KEY"
+token: "exec://echo token"
+plain: "https://example.com"
+servers: ["vault://servers/0"]
+`), 0644))
+
+	t.Run("should leave references as they are by default", func(t *testing.T) {
+		var result testStruct
+		err := New(WithSourceFiles(config)).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, "file://"+secretFile, result.Database.Password)
+	})
+
+	t.Run("should resolve references with the registered schemes", func(t *testing.T) {
+		t.Setenv("SECRETS_TEST_API_KEY", "key")
+
+		var result testStruct
+		err := New(
+			WithSourceFiles(config),
+			WithSecretResolver("file", FileSecretResolver()),
+			WithSecretResolver("env", EnvSecretResolver()),
+			WithSecretResolver("exec", ExecSecretResolver()),
+			WithSecretResolver("vault://", SecretResolverFunc(func(reference string) (string, error) {
+				return "resolved " + reference, nil
+			})),
+		).Unmarshal(&result)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", result.Database.Password)
+		require.Equal(t, "key", result.APIKey)
+		require.Equal(t, "token", result.Token)
+		require.Equal(t, "https://example.com", result.Plain)
+		require.Equal(t, []string{"resolved servers/0"}, result.Servers)
+	})
+
+	t.Run("should name the key whose reference failed", func(t *testing.T) {
+		var result testStruct
+		err := New(WithSourceFiles(config), WithSecretResolver("env", EnvSecretResolver())).Unmarshal(&result)
+
+		var secretError *SecretError
+		require.True(t, errors.As(err, &secretError))
+		require.Equal(t, "api_key", secretError.Key)
+		require.Equal(t, "env://SECRETS_TEST_API_KEY", secretError.Reference)
+	})
+}
@@ -1,8 +1,14 @@
 package confuse
 
 import (
+	"flag"
+	"io/fs"
+	"sync"
+	"time"
+
 	"dario.cat/mergo"
 	"github.com/go-playground/validator/v10"
+	"github.com/mitchellh/mapstructure"
 )
 
 // Service is the configuration for confuse, which is used to unmarshal config files
@@ -11,8 +17,17 @@ type Service struct {
 	// The first file in the list takes the lowest precedence, and the last file takes the highest precedence.
 	// E.g. if the same key is defined in both files, the value in the last file will be used.
 	// Base -> Override 1 -> Override 2 -> Override 3
+	// A path ending with a question mark, e.g. "config.local.yaml?", is optional and skipped if it doesn't exist.
 	SourceFiles []string
 
+	// Sources is the list of files to load after the SourceFiles, which can be read from their own file system, be optional, or be globs and directories that expand to several files.
+	// They take precedence in order, the same as SourceFiles.
+	Sources []SourceFile
+
+	// FS is the file system to read the SourceFiles and Sources from, unless they set their own.
+	// If this is not set, the files are read from the operating system.
+	FS fs.FS
+
 	// SourceLoaders is the list of loaders to use to load the files.
 	// The first loader in the list takes the lowest precedence, and the last loader takes the highest precedence.
 	// They take a higher precedence than SourceFiles, but lower than EnvironmentVariables.
@@ -35,6 +50,22 @@ type Service struct {
 	// By default, it is set to strcase.ToSnake.
 	JSONSchemaKeyModifier func(string) string
 
+	// JSONSchemaID is the $id of the generated JSON schemas, which is the URI they are published at.
+	// By default, it is left out.
+	JSONSchemaID string
+
+	// JSONSchemaTitle is the title of the generated JSON schemas.
+	// By default, it is "Confuse Configuration Schema".
+	JSONSchemaTitle string
+
+	// JSONSchemaDescription is the description of the generated JSON schemas.
+	// By default, it describes whether the schema is an exact or a fuzzy match of the config struct.
+	JSONSchemaDescription string
+
+	// JSONSchemaDraft is the JSON schema draft the generated JSON schemas are compatible with, such as JSONSchemaDraft07 for editors and validators that only support older drafts.
+	// By default, it is JSONSchemaDraft2020_12.
+	JSONSchemaDraft JSONSchemaDraft
+
 	// ShouldValidate is a flag to indicate whether the unmarshalled configuration should be validated against the JSON schema.
 	// If this is set to true, the unmarshalled configuration will be validated against the JSON schema.
 	ShouldValidate bool
@@ -56,7 +87,56 @@ type Service struct {
 	// By default, the separator is "__".
 	EnvironmentVariablesSeparator string
 
+	// EnvironmentVariablesListSeparator is the separator between the items of a slice or the key value pairs of a map set by an environment variable, e.g. APP__HOSTS=a,b.
+	// It also applies to any other string value decoded into a slice or a map, such as the values of flags.
+	// By default, the separator is ",".
+	EnvironmentVariablesListSeparator string
+
+	// FlagSet is the flag set to register a flag on for every leaf field of the config struct, such as --database-config.host.
+	// If this is set, the flags that are explicitly set in FlagArgs override the configuration from every other source.
+	FlagSet *flag.FlagSet
+
+	// FlagArgs is the list of command-line arguments to parse with FlagSet, without the program name.
+	FlagArgs []string
+
+	// DecodeHooks are the hooks that convert the merged values into the types of the fields, before the built-in ones.
+	// The built-in hooks support time.Duration, url.URL, ByteSize and the types that implement encoding.TextUnmarshaler, such as time.Time, net.IP and regexp.Regexp.
+	DecodeHooks []mapstructure.DecodeHookFunc
+
 	// MergoConfig is the list of options to use when merging the configuration using dario.cat/mergo.
 	// By default it just uses mergo.WithOverride.
 	MergoConfig []func(*mergo.Config)
+
+	// Profiles is the list of active profiles, such as "production" or "eu-west".
+	// For every file in SourceFiles, the files named after it with each profile, e.g. config.production.yaml for config.yaml, are loaded after it if they exist.
+	// Globs and directories are not layered with profiles.
+	Profiles []string
+
+	// ProfilesEnvironmentVariable is the name of an environment variable, such as APP_PROFILE, containing a comma separated list of additional profiles.
+	// They are active after the ones in Profiles.
+	ProfilesEnvironmentVariable string
+
+	// Formats maps file extensions to the decoders to use for source files with that extension.
+	// They take precedence over the formats registered with RegisterFormat and the built-in ones.
+	Formats map[string]Decoder
+
+	// ShouldRejectUnknownKeys is a flag to indicate whether keys in the sources that don't match any field should be rejected.
+	// If this is set to true, unmarshalling fails with an UnknownKeysError listing every unknown key and the sources it came from.
+	// Keys that only came from environment variables are ignored.
+	ShouldRejectUnknownKeys bool
+
+	// ShouldInterpolate is a flag to indicate whether references in the values should be resolved once every source is merged.
+	// ${path.to.key} is replaced with the value of another key, ${env:NAME} with the value of an environment variable, and $${ is an escaped ${.
+	ShouldInterpolate bool
+
+	// SecretResolvers maps URL schemes to the resolvers of the secrets referenced by values with that scheme, e.g. file:///run/secrets/db_password.
+	// The references are resolved once every source is merged, after interpolation.
+	SecretResolvers map[string]SecretResolver
+
+	// WatchInterval is how often Watch polls the SourceFiles for changes.
+	// By default, it is set to one second.
+	WatchInterval time.Duration
+
+	loadedSourceFiles      []SourceFile
+	loadedSourceFilesMutex sync.RWMutex
 }
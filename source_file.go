@@ -0,0 +1,209 @@
+package confuse
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// optionalSuffix marks a path passed to WithSourceFiles or WithSourceFilesFS as optional, e.g. "config.local.yaml?".
+const optionalSuffix = "?"
+
+// SourceFile is a single file to load the configuration from.
+type SourceFile struct {
+	// Path is the path of the file.
+	// When the file is read from a fs.FS, it must be a valid path within it, as described by fs.ValidPath.
+	Path string
+
+	// FS is the file system to read the file from.
+	// If it is nil, the FS of the Service is used, and if that is nil too, the file is read from the operating system.
+	FS fs.FS
+
+	// Optional marks the file as optional, so it is skipped if it doesn't exist.
+	// Errors reading or parsing the file when it does exist still fail the unmarshalling.
+	Optional bool
+
+	// Glob marks Path as a glob pattern, in the syntax of filepath.Match.
+	// It expands to every matching file, in lexical order, which may be none.
+	Glob bool
+
+	// Directory marks Path as a directory, such as a conf.d directory.
+	// It expands to every file directly inside it with a supported format, in lexical order, ignoring hidden files.
+	Directory bool
+}
+
+// newSourceFile creates a SourceFile for the path, which is optional if it ends with a question mark.
+func newSourceFile(path string, fsys fs.FS) SourceFile {
+	return SourceFile{
+		Path:     strings.TrimSuffix(path, optionalSuffix),
+		FS:       fsys,
+		Optional: strings.HasSuffix(path, optionalSuffix),
+	}
+}
+
+// isMissing reports whether the source file is optional and doesn't exist, meaning it should be skipped.
+func (s *Service) isMissing(source SourceFile) bool {
+	if !source.Optional {
+		return false
+	}
+
+	_, err := statFile(s.fileSystem(source), source.Path)
+
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// sourceList returns the SourceFiles followed by the Sources, in order of precedence.
+func (s *Service) sourceList() []SourceFile {
+	result := make([]SourceFile, 0, len(s.SourceFiles)+len(s.Sources))
+	for _, file := range s.SourceFiles {
+		result = append(result, newSourceFile(file, nil))
+	}
+
+	return append(result, s.Sources...)
+}
+
+// expandSourceFiles returns the list of files to load, with the globs and directories in Sources expanded in place,
+// and the files of the active profiles layered after each file.
+// Optional files that don't exist are left out.
+func (s *Service) expandSourceFiles() ([]SourceFile, error) {
+	var result []SourceFile
+	for _, source := range s.sourceList() {
+		if !source.Glob && !source.Directory {
+			if !s.isMissing(source) {
+				result = append(result, source)
+			}
+
+			for _, profileSource := range s.profileSourceFiles(source) {
+				if !s.isMissing(profileSource) {
+					result = append(result, profileSource)
+				}
+			}
+
+			continue
+		}
+
+		if s.isMissing(source) {
+			continue
+		}
+
+		var paths []string
+		var err error
+		if source.Glob {
+			paths, err = s.globFiles(source)
+		} else {
+			paths, err = s.directoryFiles(source)
+		}
+
+		if err != nil {
+			return nil, &FileError{File: source.Path, Err: err}
+		}
+
+		for _, filePath := range paths {
+			result = append(result, SourceFile{Path: filePath, FS: source.FS})
+		}
+	}
+
+	return result, nil
+}
+
+// globFiles returns the files matching the glob pattern of the source, in lexical order.
+func (s *Service) globFiles(source SourceFile) ([]string, error) {
+	var paths []string
+	var err error
+	if fsys := s.fileSystem(source); fsys != nil {
+		paths, err = fs.Glob(fsys, source.Path)
+	} else {
+		paths, err = filepath.Glob(source.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// directoryFiles returns the files directly inside the directory of the source that have a supported format, in lexical order.
+func (s *Service) directoryFiles(source SourceFile) ([]string, error) {
+	fsys := s.fileSystem(source)
+
+	var entries []fs.DirEntry
+	var err error
+	if fsys != nil {
+		entries, err = fs.ReadDir(fsys, source.Path)
+	} else {
+		entries, err = os.ReadDir(source.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		if _, ok := s.decoderFor(filepath.Ext(entry.Name())); !ok {
+			continue
+		}
+
+		if fsys != nil {
+			paths = append(paths, path.Join(source.Path, entry.Name()))
+		} else {
+			paths = append(paths, filepath.Join(source.Path, entry.Name()))
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// LoadedSourceFiles returns the source files that were loaded by the last call to Unmarshal, Explain or a reload by Watch.
+// It can be used to find out which of the optional source files existed.
+func (s *Service) LoadedSourceFiles() []SourceFile {
+	s.loadedSourceFilesMutex.RLock()
+	defer s.loadedSourceFilesMutex.RUnlock()
+
+	return append([]SourceFile(nil), s.loadedSourceFiles...)
+}
+
+func (s *Service) setLoadedSourceFiles(files []SourceFile) {
+	s.loadedSourceFilesMutex.Lock()
+	defer s.loadedSourceFilesMutex.Unlock()
+
+	s.loadedSourceFiles = files
+}
+
+// fileSystem returns the file system to read the source file from, or nil for the operating system.
+func (s *Service) fileSystem(source SourceFile) fs.FS {
+	if source.FS != nil {
+		return source.FS
+	}
+
+	return s.FS
+}
+
+// statFile returns the file info of the path, from the operating system if fsys is nil.
+func statFile(fsys fs.FS, path string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(path)
+	}
+
+	return fs.Stat(fsys, path)
+}
+
+// readFile reads the file at the path, from the operating system if fsys is nil.
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(path)
+	}
+
+	return fs.ReadFile(fsys, path)
+}
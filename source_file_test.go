@@ -0,0 +1,156 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SourceFilesFS(t *testing.T) {
+	type testStruct struct {
+		Host string
+		Port int
+	}
+
+	fsys := fstest.MapFS{
+		"config.yaml": {Data: []byte("host: embedded\nport: 8080\n")},
+	}
+
+	t.Run("should read every file from the FS set with WithFS", func(t *testing.T) {
+		var config testStruct
+		err := New(WithFS(fsys), WithSourceFiles("config.yaml")).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "embedded", Port: 8080}, config)
+	})
+
+	t.Run("should mix files from a FS with files on disk", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "override.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("port: 9090"), 0644))
+
+		s := New(WithSourceFilesFS(fsys, "config.yaml"), WithSourceFiles(path))
+		require.Empty(t, s.SourceFiles)
+
+		var config testStruct
+		err := s.Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "embedded", Port: 9090}, config)
+	})
+
+	t.Run("should keep the plain files in SourceFiles", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "override.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("port: 9090"), 0644))
+
+		s := New(WithFS(fsys), WithSourceFiles("config.yaml"))
+		require.Equal(t, []string{"config.yaml"}, s.SourceFiles)
+
+		s = New()
+		s.SourceFiles = []string{path}
+
+		var config testStruct
+		err := s.Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Port: 9090}, config)
+	})
+}
+
+func TestService_OptionalSourceFiles(t *testing.T) {
+	type testStruct struct {
+		Port int
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	local := filepath.Join(dir, "config.local.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("port: 8080"), 0644))
+
+	t.Run("should fail for a missing required file", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(base, local)).Unmarshal(&config)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should skip missing optional files", func(t *testing.T) {
+		s := New(WithSourceFiles(base), WithOptionalSourceFiles(local))
+
+		var config testStruct
+		err := s.Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, 8080, config.Port)
+		require.Equal(t, []SourceFile{{Path: base}}, s.LoadedSourceFiles())
+	})
+
+	t.Run("should load optional files marked with a question mark that exist", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(local, []byte("port: 9090"), 0644))
+
+		s := New(WithSourceFiles(base, local+"?"))
+
+		var config testStruct
+		err := s.Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, 9090, config.Port)
+		require.Equal(t, []SourceFile{{Path: base}, {Path: local, Optional: true}}, s.LoadedSourceFiles())
+	})
+
+	t.Run("should fail for an optional file that cannot be parsed", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(local, []byte("port: [9090"), 0644))
+
+		var config testStruct
+		err := New(WithSourceFiles(base), WithOptionalSourceFiles(local)).Unmarshal(&config)
+
+		var parseError *ParseError
+		require.ErrorAs(t, err, &parseError)
+	})
+}
+
+func TestService_SourceGlobAndDirectory(t *testing.T) {
+	type testStruct struct {
+		Host string
+		Port int
+		Name string
+	}
+
+	dir := t.TempDir()
+	confD := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(confD, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("host: base\nport: 1\nname: base"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "10-port.yaml"), []byte("port: 10"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "20-port.json"), []byte(`{"port": 20}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "30-host.toml"), []byte(`host = "toml"`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confD, ".hidden.yaml"), []byte("name: hidden"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confD, "README.md"), []byte("# Fragments"), 0644))
+
+	t.Run("should merge the files in a directory in lexical order", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(filepath.Join(dir, "config.yaml")), WithSourceDirectory(confD)).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "toml", Port: 20, Name: "base"}, config)
+	})
+
+	t.Run("should merge the files matching a glob in lexical order", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(filepath.Join(dir, "config.yaml")), WithSourceGlob(filepath.Join(confD, "*.yaml"))).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, testStruct{Host: "base", Port: 10, Name: "hidden"}, config)
+	})
+
+	t.Run("should expand globs in a FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"conf.d/a.yaml": {Data: []byte("port: 1")},
+			"conf.d/b.yaml": {Data: []byte("port: 2")},
+		}
+
+		var config testStruct
+		err := New(WithFS(fsys), WithSourceGlob("conf.d/*.yaml")).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, 2, config.Port)
+	})
+
+	t.Run("should fail for a missing directory", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceDirectory(filepath.Join(dir, "missing"))).Unmarshal(&config)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
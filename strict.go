@@ -0,0 +1,117 @@
+package confuse
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// UnknownKey is a key in the configuration sources that does not match any field of the config struct.
+type UnknownKey struct {
+	// Key is the dot separated path of the key.
+	Key string
+
+	// Origins is the list of sources that set the key, or a value nested under it.
+	Origins []Origin
+}
+
+// UnknownKeysError is returned in strict mode when the configuration sources contain keys that don't match any field of the config struct.
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+	for _, key := range e.Keys {
+		origins := make([]string, 0, len(key.Origins))
+		for _, origin := range key.Origins {
+			origins = append(origins, origin.String())
+		}
+
+		keys = append(keys, key.Key+" ("+strings.Join(origins, ", ")+")")
+	}
+
+	return "unknown configuration keys: " + strings.Join(keys, ", ")
+}
+
+var sliceIndexRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+// unknownKeys builds the error for the keys the decoder didn't use, looking up the sources they came from.
+// Keys that only came from environment variables are ignored, as the environment contains plenty of variables that are not meant for the configuration.
+func (s *Service) unknownKeys(objType reflect.Type, unused []string, origins *originTracker) error {
+	tracked := make(map[string][]Origin)
+	for key, value := range origins.values {
+		path := strings.Join(s.canonicalPath(objType, origins.paths[key]), ".")
+		for _, overridden := range value.Overridden {
+			tracked[path] = append(tracked[path], overridden.Origin)
+		}
+		tracked[path] = append(tracked[path], value.Origin)
+	}
+
+	var keys []UnknownKey
+	for _, unusedKey := range unused {
+		// The decoder names slice elements like servers[0], but the origins are tracked as servers.0
+		unusedKey = sliceIndexRegexp.ReplaceAllString(unusedKey, ".$1")
+		key := strings.Join(s.canonicalPath(objType, strings.Split(unusedKey, ".")), ".")
+
+		var matched [][]Origin
+		for path, pathOrigins := range tracked {
+			if path == key || strings.HasPrefix(path, key+".") {
+				matched = append(matched, pathOrigins)
+			}
+		}
+
+		// Values inside slices are not tracked individually, so fall back to the closest tracked parent
+		for parent := key; len(matched) == 0 && strings.Contains(parent, "."); {
+			parent = parent[:strings.LastIndex(parent, ".")]
+			if pathOrigins, ok := tracked[parent]; ok {
+				matched = append(matched, pathOrigins)
+			}
+		}
+
+		var keyOrigins []Origin
+		fromEnvironmentOnly := len(matched) > 0
+		for _, pathOrigins := range matched {
+			for _, origin := range pathOrigins {
+				if origin.Kind != OriginEnvironment {
+					fromEnvironmentOnly = false
+				}
+
+				if !containsOrigin(keyOrigins, origin) {
+					keyOrigins = append(keyOrigins, origin)
+				}
+			}
+		}
+
+		if fromEnvironmentOnly {
+			continue
+		}
+
+		sort.Slice(keyOrigins, func(i, j int) bool {
+			return keyOrigins[i].String() < keyOrigins[j].String()
+		})
+
+		keys = append(keys, UnknownKey{Key: key, Origins: keyOrigins})
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Key < keys[j].Key
+	})
+
+	return &UnknownKeysError{Keys: keys}
+}
+
+func containsOrigin(origins []Origin, origin Origin) bool {
+	for _, existing := range origins {
+		if existing == origin {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package confuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Strict(t *testing.T) {
+	type testStruct struct {
+		DatabaseConfig struct {
+			Host string
+		}
+		Servers []struct {
+			Host string
+		}
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	override := filepath.Join(dir, "override.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("database_config:\n  host: localhost\nservers:\n  - host: a\n    hots: b\n"), 0644))
+	require.NoError(t, os.WriteFile(override, []byte("databse_config:\n  host: typo\n"), 0644))
+
+	t.Run("should ignore unknown keys by default", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(base, override)).Unmarshal(&config)
+		require.NoError(t, err)
+	})
+
+	t.Run("should list every unknown key with its source", func(t *testing.T) {
+		var config testStruct
+		err := New(WithSourceFiles(base, override), WithStrict(true)).Unmarshal(&config)
+
+		var unknownKeysError *UnknownKeysError
+		require.True(t, errors.As(err, &unknownKeysError))
+		require.Equal(t, []UnknownKey{
+			{Key: "databse_config", Origins: []Origin{{Kind: OriginFile, File: override}}},
+			{Key: "servers.0.hots", Origins: []Origin{{Kind: OriginFile, File: base}}},
+		}, unknownKeysError.Keys)
+	})
+
+	t.Run("should accept keys that are spelled differently in different sources", func(t *testing.T) {
+		type spellingStruct struct {
+			Port           int `config:"port,default=8080"`
+			DatabaseConfig struct {
+				Host string
+				Port int
+			}
+		}
+
+		first := filepath.Join(dir, "first.yaml")
+		second := filepath.Join(dir, "second.yaml")
+		require.NoError(t, os.WriteFile(first, []byte("Port: 9090\ndatabase_config:\n  host: localhost\n"), 0644))
+		require.NoError(t, os.WriteFile(second, []byte("DatabaseConfig:\n  port: 5433\n"), 0644))
+
+		var config spellingStruct
+		err := New(WithSourceFiles(first, second), WithStrict(true)).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, 9090, config.Port)
+		require.Equal(t, "localhost", config.DatabaseConfig.Host)
+		require.Equal(t, 5433, config.DatabaseConfig.Port)
+	})
+
+	t.Run("should ignore unknown keys from environment variables", func(t *testing.T) {
+		t.Setenv("STRICT_TEST_UNKNOWN", "value")
+
+		valid := filepath.Join(dir, "valid.yaml")
+		require.NoError(t, os.WriteFile(valid, []byte("database_config:\n  host: localhost\n"), 0644))
+
+		var config testStruct
+		err := New(WithSourceFiles(valid), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("STRICT_TEST_"), WithStrict(true)).Unmarshal(&config)
+		require.NoError(t, err)
+
+		// A file that sets the same key is still reported
+		invalid := filepath.Join(dir, "invalid.yaml")
+		require.NoError(t, os.WriteFile(invalid, []byte("unknown: file\n"), 0644))
+
+		err = New(WithSourceFiles(valid, invalid), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("STRICT_TEST_"), WithStrict(true)).Unmarshal(&config)
+
+		var unknownKeysError *UnknownKeysError
+		require.True(t, errors.As(err, &unknownKeysError))
+		require.Equal(t, []UnknownKey{
+			{Key: "unknown", Origins: []Origin{{Kind: OriginEnvironment, EnvVar: "STRICT_TEST_UNKNOWN"}, {Kind: OriginFile, File: invalid}}},
+		}, unknownKeysError.Keys)
+	})
+}
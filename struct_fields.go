@@ -0,0 +1,167 @@
+package confuse
+
+import (
+	"reflect"
+)
+
+// fieldKey returns the key that is used for the field in generated output such as the JSON schema.
+// It is the name from the config tag if it is set, otherwise it is the field name modified by JSONSchemaKeyModifier.
+func (s *Service) fieldKey(field reflect.StructField) string {
+	configTagName, _ := extractValuesFromTag(field.Tag.Get(configTag))
+	if configTagName != "" {
+		return configTagName
+	}
+
+	return s.JSONSchemaKeyModifier(field.Name)
+}
+
+// decoderName returns the name the decoder matches map keys against first: the name from the config tag if it is set, otherwise the field name.
+func decoderName(field reflect.StructField) string {
+	name, _ := extractValuesFromTag(field.Tag.Get(configTag))
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// lookupField finds the field of the struct type t that the map key would be decoded into.
+// It matches keys the same way the decoder does, using the config tag name or the field name, as well as the key named by fieldKey.
+func (s *Service) lookupField(t reflect.Type, mapKey string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := decoderName(field)
+		if name == "-" {
+			continue
+		}
+
+		if compareName(mapKey, name) || mapKey == s.fieldKey(field) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// canonicalPath converts a path of raw map keys into the path of keys as they are named by fieldKey, by following the type t.
+// Keys that do not correspond to a struct field are left as they are.
+func (s *Service) canonicalPath(t reflect.Type, path []string) []string {
+	return s.namedPath(t, path, s.fieldKey)
+}
+
+// decoderPath converts a path of raw map keys into the path of keys as they are named by decoderName, by following the type t.
+// Keys that do not correspond to a struct field are left as they are.
+func (s *Service) decoderPath(t reflect.Type, path []string) []string {
+	return s.namedPath(t, path, decoderName)
+}
+
+func (s *Service) namedPath(t reflect.Type, path []string, name func(field reflect.StructField) string) []string {
+	result := make([]string, 0, len(path))
+	for i, key := range path {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil {
+			return append(result, path[i:]...)
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := s.lookupField(t, key)
+			if !ok {
+				return append(result, path[i:]...)
+			}
+
+			result = append(result, name(field))
+			t = field.Type
+		case reflect.Map, reflect.Slice, reflect.Array:
+			result = append(result, key)
+			t = t.Elem()
+		default:
+			return append(result, path[i:]...)
+		}
+	}
+
+	return result
+}
+
+// configField is a leaf field of the config struct, which holds a value rather than nested fields.
+type configField struct {
+	// Path is the path of keys to the field, named by fieldKey.
+	Path []string
+
+	// Field is the struct field.
+	Field reflect.StructField
+
+	// Options are the options of the config tag of the field.
+	Options map[string]string
+}
+
+// leafFields returns every leaf field of the struct type t, recursing into nested structs and pointers to structs.
+// Fields with a default value or an environment variable binding are always leaves, as they set the whole value.
+func (s *Service) leafFields(t reflect.Type, path []string) []configField {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var result []configField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := s.fieldKey(field)
+		if key == "-" {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), key)
+		_, options := extractValuesFromTag(field.Tag.Get(configTag))
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		_, hasDefault := options[defaultOption]
+		_, hasEnv := options[envOption]
+		if !hasDefault && !hasEnv && fieldType.Kind() == reflect.Struct && !isValueType(fieldType) {
+			result = append(result, s.leafFields(fieldType, fieldPath)...)
+			continue
+		}
+
+		result = append(result, configField{
+			Path:    fieldPath,
+			Field:   field,
+			Options: options,
+		})
+	}
+
+	return result
+}
+
+// isValueType reports whether the struct type t holds a single value, rather than nested configuration fields.
+func isValueType(t reflect.Type) bool {
+	return t == urlType || isSecretType(t) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setPath sets the value at the path of keys in the nested map, creating the maps along the way.
+func setPath(m map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := m[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			m[key] = nested
+		}
+
+		m = nested
+	}
+
+	m[path[len(path)-1]] = value
+}
@@ -4,69 +4,260 @@ import (
 	"dario.cat/mergo"
 	"github.com/mitchellh/mapstructure"
 	"os"
+	"reflect"
+	"strings"
 )
 
 // Unmarshal unmarshals the configuration files into the given struct.
 // It returns an error if the unmarshalling fails.
 func (s *Service) Unmarshal(obj any) error {
+	err := s.load(obj)
+	if err != nil {
+		return err
+	}
+
+	err = s.generateSchemaFiles(reflect.TypeOf(obj))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// load merges all the configured sources, decodes the result into obj and validates it.
+func (s *Service) load(obj any) error {
+	objType := reflect.TypeOf(obj)
+	fullMap, origins, err := s.merge(objType)
+	if err != nil {
+		return err
+	}
+
+	unused, err := s.decode(fullMap, obj)
+	if err != nil {
+		return err
+	}
+
+	if s.ShouldRejectUnknownKeys {
+		err = s.unknownKeys(objType, unused, origins)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.validate(obj)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// merge merges all the configured sources in order of precedence, keeping track of where every value came from.
+// The default values of the fields of objType form the lowest precedence layer.
+// The keys of every source are named after the fields of objType before merging, so different spellings of a key override each other.
+func (s *Service) merge(objType reflect.Type) (map[string]any, *originTracker, error) {
 	fullMap := make(map[string]any)
-	for _, source := range s.SourceFiles {
+	origins := newOriginTracker()
+
+	defaults, err := s.canonicalSource(s.defaultsFromType(objType), objType)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.parseStrings(defaults, objType)
+
+	err = s.mergeSource(fullMap, defaults, origins, func([]string) Origin {
+		return Origin{Kind: OriginDefault}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sourceFiles, err := s.expandSourceFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, source := range sourceFiles {
 		mappedResult, err := s.unmarshalFile(source)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		err = mergo.Merge(&fullMap, mappedResult, s.MergoConfig...)
+		mappedResult, err = s.canonicalSource(mappedResult, objType)
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+
+		if s.isENVFile(source.Path) {
+			s.parseStrings(mappedResult, objType)
+			err = s.applyIndexedPaths(fullMap, mappedResult, objType, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		err = s.mergeSource(fullMap, mappedResult, origins, func([]string) Origin {
+			return Origin{Kind: OriginFile, File: source.Path}
+		})
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	for _, loader := range s.SourceLoaders {
+	for i, loader := range s.SourceLoaders {
 		mappedResult, err := loader()
 		if err != nil {
-			return err
+			return nil, nil, &LoaderError{Index: i, Err: err}
 		}
 
-		err = mergo.Merge(&fullMap, mappedResult, s.MergoConfig...)
+		mappedResult, err = s.canonicalSource(mappedResult, objType)
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+
+		err = s.mergeSource(fullMap, mappedResult, origins, func([]string) Origin {
+			return Origin{Kind: OriginLoader, Loader: i}
+		})
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
 	if s.ShouldUseEnvironmentVariables {
-		mappedResult, err := s.unmarshalENV(os.Environ())
+		mappedResult, envVarNames, err := s.parseENV(os.Environ())
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+
+		mappedResult, err = s.canonicalSource(mappedResult, objType)
+		if err != nil {
+			return nil, nil, err
+		}
+		envVarNames = s.canonicalNames(objType, envVarNames)
+
+		s.parseStrings(mappedResult, objType)
+		err = s.applyIndexedPaths(fullMap, mappedResult, objType, nil)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		err = mergo.Merge(&fullMap, mappedResult, s.MergoConfig...)
+		err = s.mergeSource(fullMap, mappedResult, origins, func(path []string) Origin {
+			return Origin{Kind: OriginEnvironment, EnvVar: envVarName(envVarNames, path)}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The variables bound explicitly to a field are more specific, so they take precedence over the ones mapped from the name
+		mappedResult, envVarNames = s.parseBoundENV(objType)
+		mappedResult, err = s.canonicalSource(mappedResult, objType)
+		if err != nil {
+			return nil, nil, err
+		}
+		envVarNames = s.canonicalNames(objType, envVarNames)
+		s.parseStrings(mappedResult, objType)
+
+		err = s.mergeSource(fullMap, mappedResult, origins, func(path []string) Origin {
+			return Origin{Kind: OriginEnvironment, EnvVar: envVarNames[strings.Join(path, ".")]}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-		TagName:          configTag,
-		WeaklyTypedInput: true,
-		Result:           obj,
-		MatchName:        compareName,
-	})
+	if s.FlagSet != nil {
+		mappedResult, flagNames, err := s.parseFlags(objType)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mappedResult, err = s.canonicalSource(mappedResult, objType)
+		if err != nil {
+			return nil, nil, err
+		}
+		flagNames = s.canonicalNames(objType, flagNames)
+		s.parseStrings(mappedResult, objType)
+
+		err = s.mergeSource(fullMap, mappedResult, origins, func(path []string) Origin {
+			return Origin{Kind: OriginFlag, Flag: flagNames[strings.Join(path, ".")]}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = s.applyElementDefaults(fullMap, objType)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	err = decoder.Decode(fullMap)
+	// The references are resolved once every layer is merged, so they see the final values
+	if s.ShouldInterpolate {
+		err = s.interpolate(fullMap, objType)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Secrets are resolved last, so references built by interpolation are resolved too
+	if len(s.SecretResolvers) > 0 {
+		err = s.resolveSecrets(fullMap, objType)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.setLoadedSourceFiles(sourceFiles)
+
+	return fullMap, origins, nil
+}
+
+// mergeSource merges a single source into the full map, and records the origin of the values it set.
+func (s *Service) mergeSource(fullMap map[string]any, mappedResult map[string]any, origins *originTracker, originFor func(path []string) Origin) error {
+	err := mergo.Merge(&fullMap, mappedResult, s.MergoConfig...)
 	if err != nil {
 		return err
 	}
 
-	err = s.validate(obj)
+	origins.track(fullMap, mappedResult, originFor)
+
+	return nil
+}
+
+// decode decodes the merged map into obj.
+// It returns the keys of the map that did not match any field.
+func (s *Service) decode(fullMap map[string]any, obj any) ([]string, error) {
+	var metadata mapstructure.Metadata
+	decoder, err := mapstructure.NewDecoder(s.decoderConfig(obj, &metadata))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.generateSchemaFiles(obj)
+	err = decoder.Decode(fullMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.Unused, nil
+}
+
+// decodeValue decodes any input into the output pointer, with the same weakly typed conversions as decoding the configuration.
+func (s *Service) decodeValue(input any, output any) error {
+	decoder, err := mapstructure.NewDecoder(s.decoderConfig(output, nil))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return decoder.Decode(input)
+}
+
+func (s *Service) decoderConfig(result any, metadata *mapstructure.Metadata) *mapstructure.DecoderConfig {
+	return &mapstructure.DecoderConfig{
+		TagName:          configTag,
+		WeaklyTypedInput: true,
+		Result:           result,
+		MatchName:        compareName,
+		Metadata:         metadata,
+		DecodeHook:       s.decodeHook(),
+	}
 }
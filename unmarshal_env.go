@@ -1,45 +1,527 @@
 package confuse
 
 import (
-	"github.com/iancoleman/strcase"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+
+	"dario.cat/mergo"
+	"github.com/iancoleman/strcase"
 )
 
+// envDecoder decodes .env files, using the environment variable settings of the Service it is used by.
+type envDecoder struct {
+	service *Service
+}
+
+func (d envDecoder) forService(s *Service) Decoder {
+	return envDecoder{service: s}
+}
+
+func (d envDecoder) Decode(bytes []byte) (map[string]any, error) {
+	s := d.service
+	if s == nil {
+		s = New()
+	}
+
+	return s.unmarshalENVFile(bytes)
+}
+
+func (d envDecoder) Encode(values map[string]any) ([]byte, error) {
+	s := d.service
+	if s == nil {
+		s = New()
+	}
+
+	return s.marshalENV(values), nil
+}
+
+// isENVFile reports whether the file is decoded as a .env file, whose values are read like environment variables.
+func (s *Service) isENVFile(path string) bool {
+	decoder, ok := s.decoderFor(filepath.Ext(path))
+	if !ok {
+		return false
+	}
+
+	_, ok = decoder.(envDecoder)
+	return ok
+}
+
+// marshalENV writes the values as a .env file, using the names of the environment variables they are read from.
+// Lists of scalars are joined by EnvironmentVariablesListSeparator, and other lists use indexed names such as APP__SERVERS__0__HOST.
+func (s *Service) marshalENV(values map[string]any) []byte {
+	var lines []string
+	s.appendENVLines(&lines, nil, values)
+	sort.Strings(lines)
+
+	var result strings.Builder
+	for _, line := range lines {
+		result.WriteString(line)
+		result.WriteByte('\n')
+	}
+
+	return []byte(result.String())
+}
+
+func (s *Service) appendENVLines(lines *[]string, path []string, value any) {
+	switch typed := value.(type) {
+	case nil:
+	case map[string]any:
+		for key, nested := range typed {
+			s.appendENVLines(lines, append(append([]string{}, path...), key), nested)
+		}
+	case []any:
+		if list, ok := s.joinENVList(typed); ok {
+			*lines = append(*lines, s.envVarNameForPath(path)+"="+quoteDotenv(list))
+			return
+		}
+
+		for i, item := range typed {
+			s.appendENVLines(lines, append(append([]string{}, path...), strconv.Itoa(i)), item)
+		}
+	default:
+		*lines = append(*lines, s.envVarNameForPath(path)+"="+quoteDotenv(fmt.Sprint(typed)))
+	}
+}
+
+// joinENVList joins a list of scalars by EnvironmentVariablesListSeparator, or returns false if it can't be read back that way.
+func (s *Service) joinENVList(list []any) (string, bool) {
+	separator := s.EnvironmentVariablesListSeparator
+	if separator == "" {
+		separator = ","
+	}
+
+	items := make([]string, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case nil, map[string]any, []any:
+			return "", false
+		}
+
+		items[i] = fmt.Sprint(item)
+		if items[i] == "" || strings.Contains(items[i], separator) {
+			return "", false
+		}
+	}
+
+	return strings.Join(items, separator), len(items) > 0
+}
+
+// envVarNameForPath returns the name of the environment variable that is mapped to the path of keys, with EnvironmentVariablesPrefix and EnvironmentVariablesSeparator.
+func (s *Service) envVarNameForPath(path []string) string {
+	names := make([]string, len(path))
+	for i, key := range path {
+		names[i] = strcase.ToScreamingSnake(key)
+	}
+
+	return s.EnvironmentVariablesPrefix + strings.Join(names, s.EnvironmentVariablesSeparator)
+}
+
 func (s *Service) unmarshalENVFile(bytes []byte) (map[string]any, error) {
-	envVars := strings.Split(string(bytes), "\n")
+	envVars, err := parseDotenv(bytes)
+	if err != nil {
+		return nil, err
+	}
 
 	return s.unmarshalENV(envVars)
 }
 
 func (s *Service) unmarshalENV(envVars []string) (map[string]any, error) {
+	result, _, err := s.parseENV(envVars)
+
+	return result, err
+}
+
+// parseENV parses the environment variables into a nested map.
+// It also returns the name of the environment variable that set each value, keyed by the dot separated path of the value.
+func (s *Service) parseENV(envVars []string) (map[string]any, map[string]string, error) {
 	result := make(map[string]any)
+	names := make(map[string]string)
 	for _, envVar := range envVars {
-		split := strings.Split(envVar, "=")
-		key := split[0]
-		value := split[1]
+		name, value, ok := strings.Cut(envVar, "=")
+		if !ok {
+			continue
+		}
 
-		if s.EnvironmentVariablesPrefix != "" && !strings.HasPrefix(key, s.EnvironmentVariablesPrefix) {
+		if s.EnvironmentVariablesPrefix != "" && !strings.HasPrefix(name, s.EnvironmentVariablesPrefix) {
 			continue
 		}
 
-		key = strings.TrimPrefix(key, s.EnvironmentVariablesPrefix)
+		key := strings.TrimPrefix(name, s.EnvironmentVariablesPrefix)
 		key = strcase.ToSnake(key)
 		keyPath := strings.Split(key, s.EnvironmentVariablesSeparator)
 
+		// A variable that sets a key nested under the value of another one, e.g. APP__DB__HOST under APP__DB, is more specific,
+		// so it takes precedence whatever the order of the variables
 		var currentMap = result
-		for i, key := range keyPath {
-			if i == len(keyPath)-1 {
-				currentMap[key] = value
+		for i, key := range keyPath[:len(keyPath)-1] {
+			nested, ok := currentMap[key].(map[string]any)
+			if !ok {
+				delete(names, strings.Join(keyPath[:i+1], "."))
+				nested = make(map[string]any)
+				currentMap[key] = nested
+			}
+
+			currentMap = nested
+		}
+
+		last := keyPath[len(keyPath)-1]
+		if _, ok := currentMap[last].(map[string]any); ok {
+			continue
+		}
+
+		currentMap[last] = value
+		names[strings.Join(keyPath, ".")] = name
+	}
+
+	return result, names, nil
+}
+
+const envOption = "env"
+
+// parseBoundENV reads the environment variables that are bound explicitly to fields with the env option of the config tag.
+// The option lists the names of the variables separated by "|", e.g. `config:"host,env=DATABASE_HOST|PGHOST"`, and the first one that is set is used.
+// The names are used as they are, without EnvironmentVariablesPrefix.
+// It also returns the name of the environment variable that set each value, keyed by the dot separated path of the value.
+func (s *Service) parseBoundENV(t reflect.Type) (map[string]any, map[string]string) {
+	result := make(map[string]any)
+	names := make(map[string]string)
+	for _, field := range s.leafFields(t, nil) {
+		binding, ok := field.Options[envOption]
+		if !ok {
+			continue
+		}
+
+		for _, name := range strings.Split(binding, "|") {
+			value, ok := os.LookupEnv(name)
+			if !ok {
 				continue
 			}
 
-			if _, ok := currentMap[key]; !ok {
-				currentMap[key] = make(map[string]any)
+			setPath(result, field.Path, value)
+			names[strings.Join(field.Path, ".")] = name
+			break
+		}
+	}
+
+	return result, names
+}
+
+// envVarName returns the name of the environment variable that set the value at the path.
+// Slices set by indexed environment variables are tracked as a whole, so it falls back to the first variable that set a value inside it.
+func envVarName(names map[string]string, path []string) string {
+	key := strings.Join(path, ".")
+	if name, ok := names[key]; ok {
+		return name
+	}
+
+	var nested []string
+	for valueKey := range names {
+		if strings.HasPrefix(valueKey, key+".") {
+			nested = append(nested, valueKey)
+		}
+	}
+
+	if len(nested) == 0 {
+		return ""
+	}
+
+	sort.Strings(nested)
+
+	return names[nested[0]]
+}
+
+// applyIndexedPaths converts the maps in src whose keys are all indexes, like the ones built from APP__SERVERS__0__HOST, into slices, where they set a slice field of the type t.
+// Maps that set a map field are left as they are, so keys like the 404 in APP__MESSAGES__404 can be used, and maps whose type is unknown are converted.
+// Where dst already has a slice at the same path, the indexes update a copy of it, so the other elements and fields are kept when src is merged into dst.
+func (s *Service) applyIndexedPaths(dst map[string]any, src map[string]any, t reflect.Type, path []string) error {
+	for key, value := range src {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		keyType, name := s.keyType(t, key)
+		keyPath := append(append([]string{}, path...), name)
+		if isIndexedMap(nested) && isListType(keyType) {
+			slice, err := s.indexedSlice(dst[key], nested, elemType(keyType), keyPath)
+			if err != nil {
+				return err
 			}
 
-			currentMap = currentMap[key].(map[string]any)
+			src[key] = slice
+			continue
+		}
+
+		existing, _ := dst[key].(map[string]any)
+		err := s.applyIndexedPaths(existing, nested, keyType, keyPath)
+		if err != nil {
+			return err
 		}
 	}
 
+	return nil
+}
+
+// indexedSlice builds the slice for a map of indexes to values, starting from a copy of the existing slice if there is one.
+// The indexes can update the existing elements or add new ones, but not leave gaps.
+func (s *Service) indexedSlice(existing any, values map[string]any, t reflect.Type, path []string) ([]any, error) {
+	var result []any
+	if existingValue := reflect.ValueOf(existing); existing != nil && (existingValue.Kind() == reflect.Slice || existingValue.Kind() == reflect.Array) {
+		for i := 0; i < existingValue.Len(); i++ {
+			result = append(result, deepCopy(existingValue.Index(i).Interface()))
+		}
+	}
+
+	indexes := make([]int, 0, len(values))
+	keys := make(map[int]string, len(values))
+	for key := range values {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s of %s: %w", key, strings.Join(path, "."), err)
+		}
+
+		indexes = append(indexes, index)
+		keys[index] = key
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		if index > len(result) {
+			return nil, fmt.Errorf("invalid index %d of %s: the list has %d elements", index, strings.Join(path, "."), len(result))
+		}
+
+		if index == len(result) {
+			result = append(result, nil)
+		}
+
+		value := values[keys[index]]
+		indexPath := append(append([]string{}, path...), strconv.Itoa(index))
+
+		nested, ok := value.(map[string]any)
+		if !ok {
+			result[index] = value
+			continue
+		}
+
+		if isIndexedMap(nested) && isListType(t) {
+			slice, err := s.indexedSlice(result[index], nested, elemType(t), indexPath)
+			if err != nil {
+				return nil, err
+			}
+
+			result[index] = slice
+			continue
+		}
+
+		current, ok := result[index].(map[string]any)
+		if !ok {
+			current = make(map[string]any)
+		}
+
+		err := s.applyIndexedPaths(current, nested, t, indexPath)
+		if err != nil {
+			return nil, err
+		}
+
+		err = mergo.Merge(&current, nested, s.MergoConfig...)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = current
+	}
+
 	return result, nil
 }
+
+// keyType returns the type the key of a map decoded into the type t is decoded into, or nil if it is not known.
+// It also returns the name of the key as it is named by fieldKey, for errors.
+func (s *Service) keyType(t reflect.Type, key string) (reflect.Type, string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil {
+		return nil, key
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if isValueType(t) {
+			return nil, key
+		}
+
+		field, ok := s.lookupField(t, key)
+		if !ok {
+			return nil, key
+		}
+
+		return field.Type, s.fieldKey(field)
+	case reflect.Map:
+		return t.Elem(), key
+	default:
+		return nil, key
+	}
+}
+
+// isListType reports whether the type t is a slice or array, or is not known.
+func isListType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == nil || t.Kind() == reflect.Interface || t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
+
+// elemType returns the type of the elements of the slice or array type t, or nil if it is not known.
+func elemType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return nil
+	}
+
+	return t.Elem()
+}
+
+// parseStrings converts the strings of a source that can only hold strings, such as environment variables, flags and default values, into the lists and maps of the fields of the type t they set, in place.
+// A string that is a JSON array or object is decoded as JSON, e.g. ["a","b"] or {"a":1}.
+// Otherwise, slices are split by EnvironmentVariablesListSeparator, e.g. a,b,c, and maps are split into key value pairs, e.g. a=1,b=2.
+// Sources such as YAML files have their own syntax for lists and maps, so their strings are left as they are.
+func (s *Service) parseStrings(source map[string]any, t reflect.Type) {
+	s.parseStringsIn(source, t)
+}
+
+func (s *Service) parseStringsIn(value any, t reflect.Type) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			nestedType, _ := s.keyType(t, key)
+
+			// Maps of indexes set the elements of slices
+			if elem := elemType(t); elem != nil {
+				nestedType = elem
+			}
+
+			typed[key] = s.parseStringsIn(nested, nestedType)
+		}
+	case []any:
+		elem := elemType(t)
+		for i, item := range typed {
+			typed[i] = s.parseStringsIn(item, elem)
+		}
+	case string:
+		return s.parseString(typed, t)
+	}
+
+	return value
+}
+
+// parseString converts a single string into a list or map, if the type t is a slice, map or struct, see parseStrings.
+func (s *Service) parseString(value string, t reflect.Type) any {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Secrets hold the value they are decoded into
+	if t != nil && isSecretType(t) {
+		t = secretValueType(t)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+
+	if t == nil || (t.Kind() == reflect.Struct && isValueType(t)) {
+		return value
+	}
+
+	trimmed := strings.TrimSpace(value)
+	separator := s.EnvironmentVariablesListSeparator
+	if separator == "" {
+		separator = ","
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		// Strings are decoded into byte slices as they are
+		if t.Elem().Kind() == reflect.Uint8 || trimmed == "" {
+			return value
+		}
+
+		var result []any
+		if strings.HasPrefix(trimmed, "[") && json.Unmarshal([]byte(trimmed), &result) == nil {
+			return result
+		}
+
+		for _, item := range strings.Split(trimmed, separator) {
+			result = append(result, strings.TrimSpace(item))
+		}
+
+		return result
+	case reflect.Map, reflect.Struct:
+		result := make(map[string]any)
+		if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &result) == nil {
+			return result
+		}
+
+		if t.Kind() != reflect.Map || trimmed == "" {
+			return value
+		}
+
+		for _, pair := range strings.Split(trimmed, separator) {
+			key, pairValue, ok := strings.Cut(pair, "=")
+			if !ok {
+				return value
+			}
+
+			result[strings.TrimSpace(key)] = strings.TrimSpace(pairValue)
+		}
+
+		return result
+	default:
+		return value
+	}
+}
+
+// isIndexedMap reports whether every key of the map is a slice index.
+func isIndexedMap(m map[string]any) bool {
+	if len(m) == 0 {
+		return false
+	}
+
+	for key := range m {
+		if key == "" || strings.Trim(key, "0123456789") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deepCopy copies the nested maps and slices of the value, so they can be modified without changing the original.
+func deepCopy(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+		for key, nested := range typed {
+			result[key] = deepCopy(nested)
+		}
+		return result
+	case []any:
+		result := make([]any, len(typed))
+		for i, nested := range typed {
+			result[i] = deepCopy(nested)
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,191 @@
+package confuse
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_EnvironmentVariableValues(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+
+	type testStruct struct {
+		Hosts    []string
+		Ports    []int
+		Limits   map[string]int
+		Labels   map[string]string
+		Messages map[string]string
+		Servers  []server
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("servers:\n  - host: a\n    port: 1\n  - host: b\n    port: 2\n"), 0644))
+
+	load := func(t *testing.T, opts ...Option) testStruct {
+		var config testStruct
+		opts = append([]Option{WithSourceFiles(path), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("ENV_TEST_")}, opts...)
+		err := New(opts...).Unmarshal(&config)
+		require.NoError(t, err)
+
+		return config
+	}
+
+	t.Run("should split lists and maps", func(t *testing.T) {
+		t.Setenv("ENV_TEST_HOSTS", "a, b,c")
+		t.Setenv("ENV_TEST_PORTS", "80,443")
+		t.Setenv("ENV_TEST_LIMITS", "cpu=2,memory=512")
+
+		config := load(t)
+		require.Equal(t, []string{"a", "b", "c"}, config.Hosts)
+		require.Equal(t, []int{80, 443}, config.Ports)
+		require.Equal(t, map[string]int{"cpu": 2, "memory": 512}, config.Limits)
+	})
+
+	t.Run("should leave the strings of other files as they are", func(t *testing.T) {
+		yamlPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(yamlPath, []byte("hosts: \"host=a,b\"\nlabels:\n  team: a,b\n"), 0644))
+
+		var config testStruct
+		err := New(WithSourceFiles(yamlPath)).Unmarshal(&config)
+		require.NoError(t, err)
+		require.Equal(t, []string{"host=a,b"}, config.Hosts)
+		require.Equal(t, map[string]string{"team": "a,b"}, config.Labels)
+
+		t.Setenv("ENV_TEST_HOSTS", "host=a,b")
+		require.Equal(t, []string{"host=a", "b"}, load(t).Hosts)
+	})
+
+	t.Run("should use the configured list separator", func(t *testing.T) {
+		t.Setenv("ENV_TEST_HOSTS", "a;b")
+
+		config := load(t, WithEnvironmentVariablesListSeparator(";"))
+		require.Equal(t, []string{"a", "b"}, config.Hosts)
+	})
+
+	t.Run("should decode JSON values", func(t *testing.T) {
+		t.Setenv("ENV_TEST_HOSTS", `["a,1", "b"]`)
+		t.Setenv("ENV_TEST_LABELS", `{"team": "a=b"}`)
+
+		config := load(t)
+		require.Equal(t, []string{"a,1", "b"}, config.Hosts)
+		require.Equal(t, map[string]string{"team": "a=b"}, config.Labels)
+	})
+
+	t.Run("should update slice elements with indexed paths", func(t *testing.T) {
+		t.Setenv("ENV_TEST_SERVERS__1__HOST", "override")
+		t.Setenv("ENV_TEST_SERVERS__2__HOST", "c")
+
+		config := load(t)
+		require.Equal(t, []server{{Host: "a", Port: 1}, {Host: "override", Port: 2}, {Host: "c"}}, config.Servers)
+	})
+
+	t.Run("should keep indexes as the keys of maps", func(t *testing.T) {
+		t.Setenv("ENV_TEST_MESSAGES__404", "not found")
+		t.Setenv("ENV_TEST_MESSAGES__500", "internal error")
+
+		config := load(t)
+		require.Equal(t, map[string]string{"404": "not found", "500": "internal error"}, config.Messages)
+
+		envFile := filepath.Join(t.TempDir(), "config.env")
+		require.NoError(t, os.WriteFile(envFile, []byte("ENV_TEST_MESSAGES__404=missing\n"), 0644))
+
+		var fromFile testStruct
+		err := New(WithSourceFiles(envFile), WithEnvironmentVariablesPrefix("ENV_TEST_")).Unmarshal(&fromFile)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"404": "missing"}, fromFile.Messages)
+	})
+
+	t.Run("should let the variables that set nested keys win whatever the order", func(t *testing.T) {
+		s := New(WithEnvironmentVariablesPrefix("APP__"))
+		for _, envVars := range [][]string{
+			{"APP__DB=x", "APP__DB__HOST=y"},
+			{"APP__DB__HOST=y", "APP__DB=x"},
+		} {
+			result, names, err := s.parseENV(envVars)
+			require.NoError(t, err, envVars)
+			require.Equal(t, map[string]any{"db": map[string]any{"host": "y"}}, result, envVars)
+			require.Equal(t, map[string]string{"db.host": "APP__DB__HOST"}, names, envVars)
+
+			dotenv := []byte(strings.Join(envVars, "\n") + "\n")
+			result, err = envDecoder{service: s}.Decode(dotenv)
+			require.NoError(t, err, envVars)
+			require.Equal(t, map[string]any{"db": map[string]any{"host": "y"}}, result, envVars)
+		}
+
+		t.Setenv("ENV_TEST_SERVERS", `["a"]`)
+		t.Setenv("ENV_TEST_SERVERS__0__HOST", "x")
+
+		config := load(t)
+		require.Equal(t, []server{{Host: "x", Port: 1}, {Host: "b", Port: 2}}, config.Servers)
+	})
+
+	t.Run("should fail on indexes that leave gaps", func(t *testing.T) {
+		t.Setenv("ENV_TEST_SERVERS__5__HOST", "f")
+
+		var config testStruct
+		err := New(WithSourceFiles(path), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("ENV_TEST_")).Unmarshal(&config)
+		require.EqualError(t, err, "invalid index 5 of servers: the list has 2 elements")
+	})
+
+	t.Run("should fail on indexes that are out of range", func(t *testing.T) {
+		t.Setenv("ENV_TEST_SERVERS__99999999999999999999__HOST", "f")
+
+		var config testStruct
+		err := New(WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("ENV_TEST_")).Unmarshal(&config)
+		require.ErrorIs(t, err, strconv.ErrRange)
+	})
+}
+
+func TestService_EnvironmentVariableBindings(t *testing.T) {
+	type testStruct struct {
+		Database struct {
+			Host string `config:"host,env=ENV_BINDING_TEST_HOST|ENV_BINDING_TEST_PGHOST"`
+			URL  string `config:"url,env=ENV_BINDING_TEST_DATABASE_URL"`
+		} `config:"database"`
+	}
+
+	load := func(t *testing.T) testStruct {
+		var config testStruct
+		err := New(WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("ENV_BINDING_TEST_")).Unmarshal(&config)
+		require.NoError(t, err)
+
+		return config
+	}
+
+	t.Run("should use the first bound variable that is set", func(t *testing.T) {
+		t.Setenv("ENV_BINDING_TEST_PGHOST", "fallback")
+		require.Equal(t, "fallback", load(t).Database.Host)
+
+		t.Setenv("ENV_BINDING_TEST_HOST", "primary")
+		require.Equal(t, "primary", load(t).Database.Host)
+	})
+
+	t.Run("should take precedence over the variables mapped from the name", func(t *testing.T) {
+		t.Setenv("ENV_BINDING_TEST_DATABASE__URL", "mapped")
+		require.Equal(t, "mapped", load(t).Database.URL)
+
+		t.Setenv("ENV_BINDING_TEST_DATABASE_URL", "bound")
+		require.Equal(t, "bound", load(t).Database.URL)
+	})
+
+	t.Run("should override keys that are spelled differently in the files", func(t *testing.T) {
+		t.Setenv("ENV_BINDING_TEST_HOST", "bound")
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("Database:\n  Host: file\n"), 0644))
+
+		var config testStruct
+		provenance, err := New(WithSourceFiles(path), WithEnvironmentVariables(true), WithEnvironmentVariablesPrefix("ENV_BINDING_TEST_")).Explain(&config)
+		require.NoError(t, err)
+
+		require.Equal(t, "bound", config.Database.Host)
+		require.Equal(t, Origin{Kind: OriginEnvironment, EnvVar: "ENV_BINDING_TEST_HOST"}, provenance["database.host"].Origin)
+	})
+}
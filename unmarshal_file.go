@@ -1,31 +1,39 @@
 package confuse
 
 import (
-	"os"
+	"errors"
 	"path/filepath"
 )
 
-func (s *Service) unmarshalFile(filePath string) (map[string]any, error) {
-	if _, err := os.Stat(filePath); err != nil {
-		return nil, err
+func (s *Service) unmarshalFile(source SourceFile) (map[string]any, error) {
+	filePath := source.Path
+	fsys := s.fileSystem(source)
+
+	if _, err := statFile(fsys, filePath); err != nil {
+		return nil, &FileError{File: filePath, Err: err}
 	}
 
-	bytes, err := os.ReadFile(filePath)
+	bytes, err := readFile(fsys, filePath)
 	if err != nil {
-		return nil, err
+		return nil, &FileError{File: filePath, Err: err}
+	}
+
+	decoder, ok := s.decoderFor(filepath.Ext(filePath))
+	if !ok {
+		return nil, &FileError{File: filePath, Err: ErrUnsupportedFormat}
 	}
 
-	var result map[string]any
-	switch filepath.Ext(filePath) {
-	case ".json":
-		result, err = s.unmarshalJSON(bytes)
-	case ".yaml", ".yml":
-		result, err = s.unmarshalYAML(bytes)
-	case ".toml":
-		result, err = s.unmarshalTOML(bytes)
-	case ".env":
-		result, err = s.unmarshalENVFile(bytes)
+	result, err := decoder.Decode(bytes)
+	if err != nil {
+		var parseError *ParseError
+		if !errors.As(err, &parseError) {
+			parseError = &ParseError{Err: err}
+		}
+
+		parseError.File = filePath
+
+		return nil, parseError
 	}
 
-	return result, err
+	return result, nil
 }
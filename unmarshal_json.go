@@ -1,13 +1,84 @@
 package confuse
 
 import (
+	"encoding/json"
+	"errors"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
-func (s *Service) unmarshalJSON(bytes []byte) (map[string]any, error) {
+// jsonDecoder decodes .json files.
+type jsonDecoder struct{}
+
+// jsonNumbers keeps the numbers as they are written, so whole numbers are not turned into floats.
+var jsonNumbers = jsoniter.Config{UseNumber: true}.Froze()
+
+func (jsonDecoder) Decode(bytes []byte) (map[string]any, error) {
 	var result map[string]any
 
-	err := jsoniter.Unmarshal(bytes, &result)
+	err := jsonNumbers.Unmarshal(bytes, &result)
+	if err != nil {
+		return nil, jsonParseError(bytes, err)
+	}
+
+	return convertJSONNumbers(result).(map[string]any), nil
+}
+
+// convertJSONNumbers replaces the numbers in the decoded value with integers, or floats if they are not whole numbers, in place.
+// A number such as 1.0 stays a float, like in YAML and TOML.
+func convertJSONNumbers(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			typed[key] = convertJSONNumbers(nested)
+		}
+	case []any:
+		for i, item := range typed {
+			typed[i] = convertJSONNumbers(item)
+		}
+	case json.Number:
+		if number, err := typed.Int64(); err == nil {
+			return number
+		}
+
+		if number, err := typed.Float64(); err == nil {
+			return number
+		}
+
+		return typed.String()
+	}
+
+	return value
+}
+
+func (jsonDecoder) Encode(values map[string]any) ([]byte, error) {
+	bytes, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes, '\n'), nil
+}
+
+// jsonParseError finds the position of the error, which jsoniter only includes in its message.
+// The standard library parser reports it as an offset, so it is used to find it again on failure.
+func jsonParseError(bytes []byte, err error) error {
+	var result map[string]any
+	var offset int64
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch stdErr := json.Unmarshal(bytes, &result); {
+	case errors.As(stdErr, &syntaxError):
+		offset = syntaxError.Offset
+	case errors.As(stdErr, &typeError):
+		offset = typeError.Offset
+	default:
+		return &ParseError{Err: err}
+	}
+
+	// The offset is the number of bytes read before the error, so the offending byte is the one before it
+	line, column := lineAndColumn(bytes, offset-1)
 
-	return result, err
+	return &ParseError{Line: line, Column: column, Err: err}
 }
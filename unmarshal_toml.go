@@ -1,11 +1,32 @@
 package confuse
 
-import "github.com/pelletier/go-toml/v2"
+import (
+	"errors"
 
-func (s *Service) unmarshalTOML(bytes []byte) (map[string]any, error) {
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlDecoder decodes .toml files.
+type tomlDecoder struct{}
+
+func (tomlDecoder) Decode(bytes []byte) (map[string]any, error) {
 	var result map[string]any
 
 	err := toml.Unmarshal(bytes, &result)
+	if err != nil {
+		parseError := &ParseError{Err: err}
+
+		var decodeError *toml.DecodeError
+		if errors.As(err, &decodeError) {
+			parseError.Line, parseError.Column = decodeError.Position()
+		}
+
+		return nil, parseError
+	}
+
+	return result, nil
+}
 
-	return result, err
+func (tomlDecoder) Encode(values map[string]any) ([]byte, error) {
+	return toml.Marshal(values)
 }
@@ -1,13 +1,59 @@
 package confuse
 
 import (
+	"bytes"
+	"regexp"
+	"strconv"
+
 	"gopkg.in/yaml.v3"
 )
 
-func (s *Service) unmarshalYAML(bytes []byte) (map[string]any, error) {
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)(?:: column (\d+))?`)
+
+// yamlDecoder decodes .yaml and .yml files.
+type yamlDecoder struct{}
+
+func (yamlDecoder) Decode(bytes []byte) (map[string]any, error) {
 	var result map[string]any
 
 	err := yaml.Unmarshal(bytes, &result)
+	if err != nil {
+		return nil, yamlParseError(err)
+	}
+
+	return result, nil
+}
+
+func (yamlDecoder) Encode(values map[string]any) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// yamlParseError finds the position of the error, which yaml only includes in its message.
+func yamlParseError(err error) error {
+	parseError := &ParseError{Err: err}
+
+	match := yamlLineRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return parseError
+	}
+
+	parseError.Line, _ = strconv.Atoi(match[1])
+	parseError.Column, _ = strconv.Atoi(match[2])
 
-	return result, err
+	return parseError
 }
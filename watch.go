@@ -0,0 +1,87 @@
+package confuse
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// fileState is a snapshot of a source file that is used to detect changes to it.
+type fileState struct {
+	path    string
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Watch watches all the SourceFiles and Sources for changes, and re-runs the full merge, decode and validation pipeline when any of them change.
+// obj must be a pointer to the configuration struct, and is only used to know which type to unmarshal into; it is never modified.
+// On every change, onChange is called with a pointer to a newly unmarshalled value of the same type and a nil error.
+// If loading or validation fails, onChange is called with a nil value and the error instead, so the last good configuration can be kept.
+// The files are polled every WatchInterval, and Watch blocks until the context is cancelled, returning the context's error.
+func (s *Service) Watch(ctx context.Context, obj any, onChange func(obj any, err error)) error {
+	objType := reflect.TypeOf(obj)
+	if objType == nil || objType.Kind() != reflect.Ptr {
+		return errors.New("confuse: Watch requires a non-nil pointer")
+	}
+
+	interval := s.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := s.sourceFileStates()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := s.sourceFileStates()
+		if reflect.DeepEqual(previous, current) {
+			continue
+		}
+		previous = current
+
+		result := reflect.New(objType.Elem()).Interface()
+		err := s.load(result)
+		if err != nil {
+			onChange(nil, err)
+			continue
+		}
+
+		onChange(result, nil)
+	}
+}
+
+// sourceFileStates returns the current state of every file to load, in the same order.
+// The globs and directories are expanded again every time, so files that are added to or removed from them are detected.
+func (s *Service) sourceFileStates() []fileState {
+	sourceFiles := s.sourceList()
+	if expanded, err := s.expandSourceFiles(); err == nil {
+		sourceFiles = expanded
+	}
+
+	states := make([]fileState, 0, len(sourceFiles))
+	for _, source := range sourceFiles {
+		info, err := statFile(s.fileSystem(source), source.Path)
+		if err != nil {
+			states = append(states, fileState{path: source.Path})
+			continue
+		}
+
+		states = append(states, fileState{
+			path:    source.Path,
+			exists:  true,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	return states
+}
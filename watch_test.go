@@ -0,0 +1,86 @@
+package confuse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Watch(t *testing.T) {
+	type testStruct struct {
+		Port int `validate:"required"`
+	}
+
+	t.Run("should return an error if obj is not a pointer", func(t *testing.T) {
+		s := New()
+		err := s.Watch(context.Background(), testStruct{}, func(any, error) {})
+		require.Error(t, err)
+	})
+
+	t.Run("should reload on change and report validation errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("port: 8080"), 0644))
+
+		s := New(WithSourceFiles(path), WithValidation(true), WithWatchInterval(10*time.Millisecond))
+
+		type change struct {
+			obj any
+			err error
+		}
+		changes := make(chan change, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan error)
+		go func() {
+			done <- s.Watch(ctx, &testStruct{}, func(obj any, err error) {
+				changes <- change{obj, err}
+			})
+		}()
+
+		// Every write changes the size and moves the modification time forward, so the change is seen on file systems with coarse timestamps too
+		modTime := time.Now()
+		writeConfig := func(contents string) {
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+
+			modTime = modTime.Add(time.Minute)
+			require.NoError(t, os.Chtimes(path, modTime, modTime))
+		}
+
+		nextChange := func() change {
+			select {
+			case result := <-changes:
+				return result
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "timed out waiting for the change")
+				return change{}
+			}
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		writeConfig("port: 19090")
+
+		result := nextChange()
+		require.NoError(t, result.err)
+		require.Equal(t, &testStruct{Port: 19090}, result.obj)
+
+		writeConfig("port: 0\n")
+
+		result = nextChange()
+		require.Error(t, result.err)
+		require.Nil(t, result.obj)
+
+		cancel()
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for Watch to return")
+		}
+	})
+}